@@ -0,0 +1,94 @@
+package end2end
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/dal-go/dalgo/dal"
+)
+
+// Capabilities declares features supported by a dalgo driver.
+// Checks of undeclared features are skipped, checks of declared features are required to pass.
+type Capabilities struct {
+	// Queries indicates the driver executes structured queries
+	Queries bool
+
+	// OrderBy indicates the driver sorts query results by fields
+	OrderBy bool
+
+	// WhereOperators lists comparison operators the driver supports in query conditions
+	WhereOperators []dal.Operator
+
+	// OrConditions indicates the driver supports query conditions grouped by dal.Or
+	OrConditions bool
+
+	// NestedFields indicates the driver stores nested structs and updates their fields by field paths,
+	// e.g. not supported by drivers that store records as flat rows
+	NestedFields bool
+
+	// UpdateMulti indicates the driver supports ReadwriteTransaction.UpdateMulti().
+	// If not declared, UpdateMulti is still checked but dal.ErrNotSupported is reported as not supported.
+	UpdateMulti bool
+
+	// AlreadyExistsError is returned by the driver on insert of a record with ID of an existing record
+	// if it does not wrap dal.ErrRecordAlreadyExists, e.g. a native error of an underlying database
+	AlreadyExistsError error
+
+	// Increments indicates the driver supports update.Increment() values of updates
+	Increments bool
+
+	// Transactions indicates read-write transactions are atomic, e.g. rolled back on error
+	Transactions bool
+
+	// ReadAfterWriteError is returned by the driver on reads inside a read-write transaction after writes,
+	// e.g. by Firestore. Nil if reads inside a transaction see its own writes.
+	ReadAfterWriteError error
+
+	// ConflictError is returned by the driver for a transaction that conflicts with a concurrent one.
+	// Nil if conflicting transactions are serialized or retried by the driver.
+	ConflictError error
+
+	// CrossCollectionTransactions indicates a transaction can write to more than one collection.
+	// If not declared, such a transaction is still checked but dal.ErrNotSupported is reported as not supported.
+	CrossCollectionTransactions bool
+
+	// IncompleteKeys indicates the driver inserts records with incomplete keys using an ID generator
+	// passed with insert options, e.g. dal.WithRandomStringID()
+	IncompleteKeys bool
+
+	// ParentKeys indicates the driver stores records with keys that have a parent key
+	ParentKeys bool
+}
+
+// SupportsWhereOperator checks if an operator is declared in WhereOperators
+func (c Capabilities) SupportsWhereOperator(operator dal.Operator) bool {
+	return slices.Contains(c.WhereOperators, operator)
+}
+
+// skipIfWhereOperatorsNotSupported skips a check that requires where operators not declared by a driver
+func skipIfWhereOperatorsNotSupported(t *testing.T, capabilities Capabilities, operators ...dal.Operator) {
+	t.Helper()
+	for _, operator := range operators {
+		skipIfNotSupported(t, capabilities.SupportsWhereOperator(operator), "WHERE operator "+string(operator))
+	}
+}
+
+// skipIfErrNotSupported skips a check of a capability not declared by a driver that returned dal.ErrNotSupported
+func skipIfErrNotSupported(t *testing.T, declared bool, err error, capability string) {
+	t.Helper()
+	if !declared && errors.Is(err, dal.ErrNotSupported) {
+		skipIfNotSupported(t, false, capability)
+	}
+}
+
+// skipIfNotSupported skips a check that requires a capability not declared by a driver
+func skipIfNotSupported(t *testing.T, supported bool, capability string) {
+	t.Helper()
+	if !supported {
+		if node := checkNodeOf(t); node != nil {
+			node.notSupported = capability
+		}
+		t.Skipf("not supported by dalgo driver: %s", capability)
+	}
+}
@@ -0,0 +1,15 @@
+package end2end
+
+import (
+	"testing"
+
+	"github.com/dal-go/dalgo/dal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities_SupportsWhereOperator(t *testing.T) {
+	assert.False(t, Capabilities{}.SupportsWhereOperator(dal.Equal))
+	capabilities := Capabilities{WhereOperators: []dal.Operator{dal.Equal}}
+	assert.True(t, capabilities.SupportsWhereOperator(dal.Equal))
+	assert.False(t, capabilities.SupportsWhereOperator(dal.GreaterThen))
+}
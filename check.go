@@ -0,0 +1,78 @@
+package end2end
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dal-go/dalgo-end2end-tests/report"
+)
+
+// checkNode links a running (sub)test to a report of the TestDalgoDBWithOptions run it belongs to
+type checkNode struct {
+	report       *report.Report
+	filter       checkFilter
+	path         string // name of a check relative to the run, e.g. "query/SELECT ID FROM Cities/limit=3"
+	notSupported string // capability that caused a skip
+}
+
+var (
+	checkNodesMutex sync.Mutex
+	checkNodes      = make(map[*testing.T]*checkNode)
+)
+
+func bindCheckNode(t *testing.T, node *checkNode) {
+	checkNodesMutex.Lock()
+	checkNodes[t] = node
+	checkNodesMutex.Unlock()
+	t.Cleanup(func() {
+		checkNodesMutex.Lock()
+		delete(checkNodes, t)
+		checkNodesMutex.Unlock()
+	})
+}
+
+func checkNodeOf(t *testing.T) *checkNode {
+	checkNodesMutex.Lock()
+	defer checkNodesMutex.Unlock()
+	return checkNodes[t]
+}
+
+// check runs f as a subtest of t like t.Run() does and records its outcome to a report of the run.
+// Checks not selected by Options.Run & Options.Skip are neither started nor reported, and true is returned.
+func check(t *testing.T, name string, f func(t *testing.T)) bool {
+	parent := checkNodeOf(t)
+	path := name
+	if parent != nil {
+		if parent.path != "" {
+			path = parent.path + "/" + name
+		}
+		if !parent.filter.selected(path) {
+			return true
+		}
+	}
+	return t.Run(name, func(t *testing.T) {
+		if parent != nil {
+			node := &checkNode{report: parent.report, filter: parent.filter, path: path}
+			bindCheckNode(t, node)
+			started := time.Now()
+			t.Cleanup(func() {
+				node.record(t, time.Since(started))
+			})
+		}
+		f(t)
+	})
+}
+
+func (node *checkNode) record(t *testing.T, duration time.Duration) {
+	switch {
+	case t.Failed():
+		node.report.Add(node.path, report.Fail, duration, "")
+	case t.Skipped() && node.notSupported != "":
+		node.report.Add(node.path, report.NotSupported, duration, node.notSupported)
+	case t.Skipped():
+		node.report.Add(node.path, report.Skip, duration, "")
+	default:
+		node.report.Add(node.path, report.Pass, duration, "")
+	}
+}
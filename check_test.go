@@ -0,0 +1,83 @@
+package end2end
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/report"
+	"github.com/dal-go/dalgo/mocks/mock_dal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCheck(t *testing.T) {
+	r := report.NewReport("test", Version)
+	bindCheckNode(t, &checkNode{report: r})
+
+	check(t, "parent", func(t *testing.T) {
+		check(t, "passes", func(t *testing.T) {})
+		check(t, "skips", func(t *testing.T) {
+			t.Skip("skipped")
+		})
+		check(t, "not supported", func(t *testing.T) {
+			skipIfNotSupported(t, false, "something")
+		})
+	})
+
+	expected := map[string]report.Outcome{
+		"parent":               report.Pass,
+		"parent/passes":        report.Pass,
+		"parent/skips":         report.Skip,
+		"parent/not supported": report.NotSupported,
+	}
+	for name, outcome := range expected {
+		actual, found := r.Outcome(name)
+		assert.True(t, found, name)
+		assert.Equal(t, outcome, actual, name)
+	}
+}
+
+func TestDalgoDBWithOptions_report(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := mock_dal.NewMockDB(ctrl)
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	t.Run("run", func(t *testing.T) {
+		TestDalgoDBWithOptions(t, db, Options{
+			DriverName: "mock",
+			ReportPath: reportPath,
+			Run:        SuiteQuery,
+		})
+	})
+
+	r, err := report.ReadFile(reportPath)
+	require.NoError(t, err)
+	assert.Equal(t, "mock", r.Driver)
+	require.Len(t, r.Checks, 1)
+	assert.Equal(t, report.Check{Name: "query", Outcome: report.NotSupported, Reason: "queries", DurationMs: r.Checks[0].DurationMs}, r.Checks[0])
+}
+
+func TestDalgoDBWithOptions_parallelReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	db := mock_dal.NewMockDB(ctrl)
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+
+	t.Run("run", func(t *testing.T) {
+		TestDalgoDBWithOptions(t, db, Options{
+			DriverName: "mock",
+			ReportPath: reportPath,
+			Parallel:   true,
+			Run:        SuiteQuery,
+		})
+	})
+
+	// The report is saved after parallel sub-suites complete
+	r, err := report.ReadFile(reportPath)
+	require.NoError(t, err)
+	outcome, found := r.Outcome(SuiteQuery)
+	assert.True(t, found)
+	assert.Equal(t, report.NotSupported, outcome)
+}
@@ -0,0 +1,70 @@
+// Command dalgo-e2e-matrix renders a compatibility matrix of dalgo drivers from JSON conformance reports.
+//
+// Usage:
+//
+//	dalgo-e2e-matrix [-format markdown|html] [-o output] report1.json report2.json ...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dal-go/dalgo-end2end-tests/report"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) (err error) {
+	flags := flag.NewFlagSet("dalgo-e2e-matrix", flag.ContinueOnError)
+	format := flags.String("format", "markdown", "output format: markdown or html")
+	output := flags.String("o", "", "file to write the matrix to, stdout if empty")
+	if err = flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return fmt.Errorf("at least 1 report file is expected")
+	}
+
+	reports := make([]*report.Report, flags.NArg())
+	for i, path := range flags.Args() {
+		r, err := report.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if r.Driver == "" {
+			r.Driver = path
+		}
+		reports[i] = r
+	}
+	m := newMatrix(reports)
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to close output file: %w", closeErr)
+			}
+		}()
+		w = f
+	}
+
+	switch *format {
+	case "markdown", "md":
+		return m.writeMarkdown(w)
+	case "html":
+		return m.writeHTML(w)
+	default:
+		return fmt.Errorf("unknown format: %s", *format)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dal-go/dalgo-end2end-tests/report"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestReports(t *testing.T) (paths []string) {
+	dir := t.TempDir()
+
+	r1 := report.NewReport("dalgo2memory", "0.0.2")
+	r1.Add("single/get1", report.Pass, time.Millisecond, "")
+	r1.Add("query/ORDER BY Population/descending", report.Pass, time.Millisecond, "")
+
+	r2 := report.NewReport("dalgo2sql", "0.0.2")
+	r2.Add("single/get1", report.Fail, time.Millisecond, "")
+	r2.Add("query/ORDER BY Population/descending", report.NotSupported, 0, "ORDER BY")
+	r2.Add("multi/update_2_records", report.Skip, 0, "")
+
+	for i, r := range []*report.Report{r1, r2} {
+		path := filepath.Join(dir, []string{"r1.json", "r2.json"}[i])
+		require.NoError(t, report.WriteFile(path, r))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestRun_markdown(t *testing.T) {
+	paths := writeTestReports(t)
+	var out bytes.Buffer
+	require.NoError(t, run(paths, &out))
+	lines := strings.Split(out.String(), "\n")
+	assert.Equal(t, []string{
+		"| Check | dalgo2memory | dalgo2sql |",
+		"|---|---|---|",
+		"| single/get1 | ✅ | ❌ |",
+		"| query/ORDER BY Population/descending | ✅ | ➖ |",
+		"| multi/update_2_records |  | ⏭️ |",
+	}, lines[:5])
+}
+
+func TestRun_html(t *testing.T) {
+	paths := writeTestReports(t)
+	output := filepath.Join(t.TempDir(), "matrix.html")
+	var out bytes.Buffer
+	require.NoError(t, run(append([]string{"-format", "html", "-o", output}, paths...), &out))
+	assert.Empty(t, out.String())
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<th>dalgo2memory</th><th>dalgo2sql</th>")
+	assert.Contains(t, string(data), `<tr><td>single/get1</td><td title="pass">✅</td><td title="fail">❌</td></tr>`)
+}
+
+func TestRun_errors(t *testing.T) {
+	var out bytes.Buffer
+	assert.Error(t, run(nil, &out), "no reports")
+	assert.Error(t, run([]string{filepath.Join(t.TempDir(), "missing.json")}, &out), "missing report")
+	assert.Error(t, run(append([]string{"-format", "pdf"}, writeTestReports(t)...), &out), "unknown format")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/dal-go/dalgo-end2end-tests/report"
+)
+
+// matrix is a table of check outcomes, a row per check and a column per driver
+type matrix struct {
+	drivers  []string
+	checks   []string
+	outcomes map[string][]report.Outcome // outcomes by check name, indexed same as drivers
+}
+
+func newMatrix(reports []*report.Report) matrix {
+	m := matrix{
+		drivers:  make([]string, len(reports)),
+		outcomes: make(map[string][]report.Outcome),
+	}
+	for i, r := range reports {
+		m.drivers[i] = r.Driver
+		for _, c := range r.Checks {
+			outcomes, ok := m.outcomes[c.Name]
+			if !ok {
+				outcomes = make([]report.Outcome, len(reports))
+				m.outcomes[c.Name] = outcomes
+				m.checks = append(m.checks, c.Name)
+			}
+			outcomes[i] = c.Outcome
+		}
+	}
+	return m
+}
+
+func outcomeSymbol(outcome report.Outcome) string {
+	switch outcome {
+	case report.Pass:
+		return "✅"
+	case report.Fail:
+		return "❌"
+	case report.Skip:
+		return "⏭️"
+	case report.NotSupported:
+		return "➖"
+	case "":
+		return "" // check has not been executed against a driver
+	default:
+		return string(outcome)
+	}
+}
+
+func (m matrix) writeMarkdown(w io.Writer) error {
+	escape := func(s string) string {
+		return strings.ReplaceAll(s, "|", `\|`)
+	}
+	var sb strings.Builder
+	sb.WriteString("| Check |")
+	for _, driver := range m.drivers {
+		sb.WriteString(" " + escape(driver) + " |")
+	}
+	sb.WriteString("\n|---|")
+	sb.WriteString(strings.Repeat("---|", len(m.drivers)))
+	sb.WriteString("\n")
+	for _, name := range m.checks {
+		sb.WriteString("| " + escape(name) + " |")
+		for _, outcome := range m.outcomes[name] {
+			sb.WriteString(" " + outcomeSymbol(outcome) + " |")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n✅ pass, ❌ fail, ⏭️ skip, ➖ not supported\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (m matrix) writeHTML(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("<table>\n<thead>\n<tr><th>Check</th>")
+	for _, driver := range m.drivers {
+		sb.WriteString("<th>" + html.EscapeString(driver) + "</th>")
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, name := range m.checks {
+		sb.WriteString("<tr><td>" + html.EscapeString(name) + "</td>")
+		for _, outcome := range m.outcomes[name] {
+			sb.WriteString(fmt.Sprintf(`<td title="%s">%s</td>`, outcome, outcomeSymbol(outcome)))
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
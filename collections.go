@@ -0,0 +1,71 @@
+package end2end
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/dal-go/dalgo-end2end-tests/models"
+)
+
+const (
+	singleCollectionName        = "Single"
+	updateCollectionName        = "Update"
+	transactionsCollectionName  = "Transactions"
+	concurrencyCollectionName   = "Concurrency"
+	contextCollectionName       = "Context"
+	citiesCollectionName        = "Cities"
+	cityLanguagesCollectionName = "CityLanguages"
+	propertyCollectionName      = "Property"
+)
+
+// collectionNames holds names of collections used by a single TestDalgoDBWithOptions run.
+// Names are namespaced by a run ID so concurrent runs sharing a database do not see each other's records.
+// Each sub-suite has own collections, so sub-suites running in parallel do not see each other's records.
+type collectionNames struct {
+	single        string
+	kind1         string // 1st collection of the multi sub-suite
+	kind2         string // 2nd collection of the multi sub-suite
+	update        string
+	transactions  string
+	concurrency   string
+	context       string
+	cities        string
+	cityLanguages string
+	property      string
+}
+
+func newCollectionNames(prefix, runID string) collectionNames {
+	namespace := prefix + runID + "_"
+	return collectionNames{
+		single:        namespace + singleCollectionName,
+		kind1:         namespace + e2eTestKind1Name,
+		kind2:         namespace + e2eTestKind2Name,
+		update:        namespace + updateCollectionName,
+		transactions:  namespace + transactionsCollectionName,
+		concurrency:   namespace + concurrencyCollectionName,
+		context:       namespace + contextCollectionName,
+		cities:        namespace + citiesCollectionName,
+		cityLanguages: namespace + cityLanguagesCollectionName,
+		property:      namespace + propertyCollectionName,
+	}
+}
+
+// legacyCollectionNames are fixed names used by TestDalgoDB, as drivers may have tables provisioned for them
+func legacyCollectionNames() collectionNames {
+	return collectionNames{
+		single:        E2ETestKind1,
+		kind1:         E2ETestKind1,
+		kind2:         E2ETestKind2,
+		update:        TestEntitiesNamePrefix + updateCollectionName,
+		transactions:  TestEntitiesNamePrefix + transactionsCollectionName,
+		concurrency:   TestEntitiesNamePrefix + concurrencyCollectionName,
+		context:       TestEntitiesNamePrefix + contextCollectionName,
+		cities:        models.CitiesCollection,
+		cityLanguages: TestEntitiesNamePrefix + cityLanguagesCollectionName,
+		property:      TestEntitiesNamePrefix + propertyCollectionName,
+	}
+}
+
+func newRunID() string {
+	return fmt.Sprintf("%08x", rand.Uint32())
+}
@@ -0,0 +1,37 @@
+package end2end
+
+import (
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCollectionNames(t *testing.T) {
+	names := newCollectionNames(TestEntitiesNamePrefix, "run1")
+	assert.Equal(t, collectionNames{
+		single:        "DalgoE2E_run1_Single",
+		kind1:         "DalgoE2E_run1_E2ETest1",
+		kind2:         "DalgoE2E_run1_E2ETest2",
+		update:        "DalgoE2E_run1_Update",
+		transactions:  "DalgoE2E_run1_Transactions",
+		concurrency:   "DalgoE2E_run1_Concurrency",
+		context:       "DalgoE2E_run1_Context",
+		cities:        "DalgoE2E_run1_Cities",
+		cityLanguages: "DalgoE2E_run1_CityLanguages",
+		property:      "DalgoE2E_run1_Property",
+	}, names)
+}
+
+func TestLegacyCollectionNames(t *testing.T) {
+	names := legacyCollectionNames()
+	assert.Equal(t, E2ETestKind1, names.kind1)
+	assert.Equal(t, E2ETestKind2, names.kind2)
+	assert.Equal(t, models.CitiesCollection, names.cities)
+}
+
+func TestNewRunID(t *testing.T) {
+	runID := newRunID()
+	assert.Len(t, runID, 8)
+	assert.NotEqual(t, runID, newRunID())
+}
@@ -0,0 +1,42 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultConsistencyTimeout = 10 * time.Second
+	consistencyPollMinDelay   = 10 * time.Millisecond
+	consistencyPollMaxDelay   = time.Second
+)
+
+// awaitConsistency polls a condition with exponential backoff until it succeeds or timeout expires.
+// It is used by checks that write and then read through queries from eventually consistent databases.
+func awaitConsistency(ctx context.Context, timeout time.Duration, condition func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	delay := consistencyPollMinDelay
+	for {
+		err := condition(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("expected state has not been observed within %v: %w", timeout, err)
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, consistencyPollMaxDelay)
+	}
+}
+
+// whenConsistent checks a condition once, or polls it until it succeeds if the database is eventually consistent.
+// It should wrap every read through a query that must reflect preceding writes.
+func (o Options) whenConsistent(ctx context.Context, condition func(ctx context.Context) error) error {
+	if !o.EventuallyConsistent {
+		return condition(ctx)
+	}
+	return awaitConsistency(ctx, o.consistencyTimeout(), condition)
+}
@@ -0,0 +1,52 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAwaitConsistency(t *testing.T) {
+	t.Run("eventually_consistent", func(t *testing.T) {
+		attempts := 0
+		err := awaitConsistency(context.Background(), time.Second, func(ctx context.Context) error {
+			if attempts++; attempts < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+	})
+	t.Run("timeout", func(t *testing.T) {
+		errNotYet := errors.New("not yet")
+		err := awaitConsistency(context.Background(), 50*time.Millisecond, func(ctx context.Context) error {
+			return errNotYet
+		})
+		assert.ErrorIs(t, err, errNotYet)
+	})
+}
+
+func TestOptions_whenConsistent(t *testing.T) {
+	errNotYet := errors.New("not yet")
+	attempts := 0
+	notYetTwice := func(ctx context.Context) error {
+		if attempts++; attempts < 3 {
+			return errNotYet
+		}
+		return nil
+	}
+	t.Run("strongly_consistent", func(t *testing.T) {
+		attempts = 0
+		assert.ErrorIs(t, Options{}.whenConsistent(context.Background(), notYetTwice), errNotYet)
+		assert.Equal(t, 1, attempts)
+	})
+	t.Run("eventually_consistent", func(t *testing.T) {
+		attempts = 0
+		assert.NoError(t, Options{EventuallyConsistent: true}.whenConsistent(context.Background(), notYetTwice))
+		assert.Equal(t, 3, attempts)
+	})
+}
@@ -0,0 +1,218 @@
+package end2end
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/dal-go/dalgo/dal"
+)
+
+// errorClass is how an error returned by a driver is classified for comparison across drivers
+type errorClass string
+
+const (
+	noError           errorClass = ""
+	errorNotFound     errorClass = "not-found"
+	errorExists       errorClass = "already-exists"
+	errorNotSupported errorClass = "not-supported"
+	errorCanceled     errorClass = "canceled"
+	errorDeadline     errorClass = "deadline-exceeded"
+	errorUnclassified errorClass = "error"
+)
+
+const observedRecordsSep = "\n\t"
+
+func classifyError(err error) errorClass {
+	switch {
+	case err == nil:
+		return noError
+	case dal.IsNotFound(err):
+		return errorNotFound
+	case errors.Is(err, dal.ErrRecordAlreadyExists):
+		return errorExists
+	case errors.Is(err, dal.ErrNotSupported):
+		return errorNotSupported
+	case errors.Is(err, context.Canceled):
+		return errorCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorDeadline
+	default:
+		return errorUnclassified
+	}
+}
+
+// observedRecord is a record returned by a driver in a form comparable across drivers
+type observedRecord struct {
+	Key    string
+	Exists bool
+	Data   any // record data normalized through JSON
+}
+
+func (r observedRecord) String() string {
+	if !r.Exists {
+		return r.Key + " (missing)"
+	}
+	data, _ := json.Marshal(r.Data)
+	return fmt.Sprintf("%s %s", r.Key, data)
+}
+
+// observation is what an operation returned
+type observation struct {
+	Records []observedRecord
+	Error   errorClass
+	err     error // original error, not compared
+}
+
+// operation is a step that is executed against a database and observed
+type operation struct {
+	name           string
+	ordered        bool           // false if records can be returned in any order, e.g. a query without ORDER BY
+	notSupported   string         // capability required by the operation but not declared by a driver
+	whereOperators []dal.Operator // WHERE operators used by the operation
+	run            func(ctx context.Context, db dal.DB) observation
+}
+
+// diff describes differences between observations of the same operation, empty if there are none
+func (op operation) diff(expected, actual observation) (differences []string) {
+	if expected.Error != actual.Error {
+		differences = append(differences,
+			fmt.Sprintf("error: expected %q, got %q (%v)", expected.Error, actual.Error, actual.err))
+	}
+	expectedRecords, actualRecords := expected.Records, actual.Records
+	if !op.ordered {
+		expectedRecords, actualRecords = sortedByKey(expectedRecords), sortedByKey(actualRecords)
+	}
+	if !reflect.DeepEqual(expectedRecords, actualRecords) {
+		differences = append(differences, fmt.Sprintf("records:\nexpected:%s%s\nactual:%s%s",
+			observedRecordsSep, joinRecords(expectedRecords),
+			observedRecordsSep, joinRecords(actualRecords)))
+	}
+	return differences
+}
+
+func sortedByKey(records []observedRecord) []observedRecord {
+	records = slices.Clone(records)
+	slices.SortFunc(records, func(a, b observedRecord) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return records
+}
+
+func joinRecords(records []observedRecord) string {
+	s := make([]string, len(records))
+	for i, r := range records {
+		s[i] = r.String()
+	}
+	return strings.Join(s, observedRecordsSep)
+}
+
+func observeRecords(records []dal.Record) []observedRecord {
+	observed := make([]observedRecord, len(records))
+	for i, record := range records {
+		observed[i] = observedRecord{Key: record.Key().String()}
+		if record.Error() != nil || !record.Exists() {
+			continue
+		}
+		observed[i].Exists = true
+		if data := record.Data(); data != nil {
+			if b, err := json.Marshal(data); err == nil {
+				_ = json.Unmarshal(b, &observed[i].Data)
+			}
+		}
+	}
+	return observed
+}
+
+func getOperation(key *dal.Key) operation {
+	return operation{
+		name: fmt.Sprintf("Get %v", key.ID),
+		run: func(ctx context.Context, db dal.DB) observation {
+			record := dal.NewRecordWithData(key, new(TestData))
+			err := db.Get(ctx, record)
+			if dal.IsNotFound(err) {
+				return observation{Records: observeRecords([]dal.Record{record})}
+			}
+			return observation{Records: observeRecords([]dal.Record{record}), Error: classifyError(err), err: err}
+		},
+	}
+}
+
+func existsOperation(key *dal.Key) operation {
+	return operation{
+		name: fmt.Sprintf("Exists %v", key.ID),
+		run: func(ctx context.Context, db dal.DB) observation {
+			exists, err := db.Exists(ctx, key)
+			return observation{
+				Records: []observedRecord{{Key: key.String(), Exists: exists}},
+				Error:   classifyError(err),
+				err:     err,
+			}
+		},
+	}
+}
+
+func getMultiOperation(keys ...*dal.Key) operation {
+	return operation{
+		name:    "GetMulti " + joinIDs(keys),
+		ordered: true,
+		run: func(ctx context.Context, db dal.DB) observation {
+			records := make([]dal.Record, len(keys))
+			for i, key := range keys {
+				records[i] = dal.NewRecordWithData(key, new(TestData))
+			}
+			err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+				return tx.GetMulti(ctx, records)
+			})
+			return observation{Records: observeRecords(records), Error: classifyError(err), err: err}
+		},
+	}
+}
+
+func writeOperation(name string, write func(ctx context.Context, tx dal.ReadwriteTransaction) error) operation {
+	return operation{
+		name: name,
+		run: func(ctx context.Context, db dal.DB) observation {
+			err := db.RunReadwriteTransaction(ctx, write)
+			return observation{Error: classifyError(err), err: err}
+		},
+	}
+}
+
+// setupOperation prepares data with a helper shared with the suite, only its error is observed
+func setupOperation(name string, setup func(ctx context.Context, db dal.DB) error) operation {
+	return operation{
+		name: name,
+		run: func(ctx context.Context, db dal.DB) observation {
+			err := setup(ctx, db)
+			return observation{Error: classifyError(err), err: err}
+		},
+	}
+}
+
+func queryOperation(name string, query dal.Query, ordered bool) operation {
+	return operation{
+		name:    name,
+		ordered: ordered,
+		run: func(ctx context.Context, db dal.DB) observation {
+			var records []dal.Record
+			err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) (err error) {
+				records, err = dal.ExecuteQueryAndReadAllToRecords(ctx, query, tx)
+				return err
+			})
+			return observation{Records: observeRecords(records), Error: classifyError(err), err: err}
+		},
+	}
+}
+
+func joinIDs(keys []*dal.Key) string {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = fmt.Sprint(key.ID)
+	}
+	return strings.Join(ids, ",")
+}
@@ -4,13 +4,38 @@ import (
 	"context"
 	"testing"
 
+	"github.com/dal-go/dalgo-end2end-tests/report"
 	"github.com/dal-go/dalgo/dal"
 )
 
-var runSingleAndMulti = true // test hook to optionally skip single/multi in specialized tests
+// legacySuites are sub-suites run by TestDalgoDB
+var legacySuites = []string{SuiteSingle, SuiteMulti, SuiteQuery}
 
-// TestDalgoDB tests a dalgo DB implementation
+// TestDalgoDB tests a dalgo DB implementation.
+// It is a shortcut for TestDalgoDBWithOptions that allows to declare more facts about a driver.
+// Unlike TestDalgoDBWithOptions it uses fixed names of collections: E2ETestKind1, E2ETestKind2 & models.CitiesCollection,
+// and runs only the sub-suites of earlier versions, so it does not write to collections a driver may have not provisioned.
 func TestDalgoDB(t *testing.T, db dal.DB, errQuerySupport error, eventuallyConsistent bool) {
+	queries := errQuerySupport == nil
+	var whereOperators []dal.Operator
+	if queries {
+		whereOperators = []dal.Operator{dal.Equal}
+	}
+	TestDalgoDBWithOptions(t, db, Options{
+		Capabilities: Capabilities{
+			Queries:        queries,
+			OrderBy:        queries,
+			WhereOperators: whereOperators,
+		},
+		EventuallyConsistent:  eventuallyConsistent,
+		Suites:                legacySuites,
+		errQuerySupport:       errQuerySupport,
+		legacyCollectionNames: true,
+	})
+}
+
+// TestDalgoDBWithOptions tests a dalgo DB implementation as configured by options
+func TestDalgoDBWithOptions(t *testing.T, db dal.DB, options Options) {
 	if t == nil {
 		panic("t == nil")
 	}
@@ -19,21 +44,73 @@ func TestDalgoDB(t *testing.T, db dal.DB, errQuerySupport error, eventuallyConsi
 	}
 
 	ctx := context.Background()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		t.Cleanup(cancel) // not deferred as parallel sub-suites run after this function returns
+	}
 
-	if runSingleAndMulti {
-		t.Run("single", func(t *testing.T) {
-			singleOperationsTest(ctx, t, db)
-		})
-		t.Run("multi", func(t *testing.T) {
-			multiOperationsTest(ctx, t, db)
+	filter, err := options.checkFilter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := report.NewReport(options.DriverName, Version)
+	bindCheckNode(t, &checkNode{report: r, filter: filter})
+	if reportPath := options.reportPath(); reportPath != "" {
+		if r.Driver == "" {
+			if adapter := db.Adapter(); adapter != nil {
+				r.Driver = adapter.Name()
+			}
+		}
+		t.Cleanup(func() {
+			if err := report.WriteFile(reportPath, r); err != nil {
+				t.Errorf("failed to save report: %v", err)
+			}
 		})
 	}
 
-	t.Run("query", func(t *testing.T) {
-		if errQuerySupport == nil {
-			queryOperationsTest(ctx, t, db, eventuallyConsistent)
-		} else {
-			t.Skip("query not supported by dalgo driver or underlying DB:", errQuerySupport)
+	collections := legacyCollectionNames()
+	if !options.legacyCollectionNames {
+		runID := options.RunID
+		if runID == "" {
+			runID = newRunID()
 		}
+		t.Logf("run ID: %s", runID)
+		collections = newCollectionNames(options.collectionPrefix(), runID)
+	}
+
+	check(t, SuiteSingle, func(t *testing.T) {
+		options.parallel(t)
+		singleOperationsTest(ctx, t, db, options.Capabilities, collections.single)
+	})
+	check(t, SuiteMulti, func(t *testing.T) {
+		options.parallel(t)
+		multiOperationsTest(ctx, t, db, options.Capabilities, collections.kind1, collections.kind2)
+	})
+	check(t, SuiteUpdate, func(t *testing.T) {
+		options.parallel(t)
+		updateOperationsTest(ctx, t, db, options, collections.update)
+	})
+	check(t, SuiteTransactions, func(t *testing.T) {
+		options.parallel(t)
+		transactionsOperationsTest(ctx, t, db, options.Capabilities, collections.transactions)
+	})
+	check(t, SuiteConcurrency, func(t *testing.T) {
+		options.parallel(t)
+		skipIfNotSupported(t, options.Capabilities.Transactions, "transactions")
+		concurrencyOperationsTest(ctx, t, db, options.Capabilities, collections.concurrency)
+	})
+	check(t, SuiteContext, func(t *testing.T) {
+		options.parallel(t)
+		contextOperationsTest(ctx, t, db, options.Capabilities, collections.context)
+	})
+	check(t, SuiteQuery, func(t *testing.T) {
+		options.parallel(t)
+		skipIfNotSupported(t, options.Capabilities.Queries, options.queriesCapability())
+		queryOperationsTest(ctx, t, db, options, collections.cities, collections.cityLanguages)
+	})
+	check(t, SuiteProperty, func(t *testing.T) {
+		options.parallel(t)
+		propertyOperationsTest(ctx, t, db, options, collections.property)
 	})
 }
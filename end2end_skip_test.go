@@ -1,10 +1,18 @@
 package end2end
 
 import (
+	"context"
 	"errors"
+	"path/filepath"
 	"testing"
 
+	"github.com/dal-go/dalgo-end2end-tests/memdb"
+	"github.com/dal-go/dalgo-end2end-tests/report"
+	"github.com/dal-go/dalgo/dal"
 	"github.com/dal-go/dalgo/mocks/mock_dal"
+	"github.com/dal-go/dalgo/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
@@ -17,8 +25,87 @@ func TestQueryUnsupportedIsSkipped(t *testing.T) {
 	db := mock_dal.NewMockDB(ctrl)
 
 	// Disable single/multi to reach the query branch only
-	runSingleAndMulti = false
-	t.Cleanup(func() { runSingleAndMulti = true })
+	*runFlag = SuiteQuery
+	t.Cleanup(func() { *runFlag = "" })
 
 	TestDalgoDB(t, db, errors.New("queries not supported"), true)
 }
+
+func TestDalgoDBWithOptions_querySuiteOnly(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No expectations are needed as single & multi suites are not selected and queries are not supported
+	db := mock_dal.NewMockDB(ctrl)
+
+	TestDalgoDBWithOptions(t, db, Options{
+		Capabilities: Capabilities{Queries: false},
+		Run:          SuiteQuery,
+	})
+}
+
+// noUpdateMultiDB is a driver that does not support ReadwriteTransaction.UpdateMulti()
+type noUpdateMultiDB struct {
+	dal.DB
+}
+
+func (db noUpdateMultiDB) RunReadwriteTransaction(ctx context.Context, f dal.RWTxWorker, options ...dal.TransactionOption) error {
+	return db.DB.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return f(ctx, noUpdateMultiTx{ReadwriteTransaction: tx})
+	}, options...)
+}
+
+type noUpdateMultiTx struct {
+	dal.ReadwriteTransaction
+}
+
+func (noUpdateMultiTx) UpdateMulti(context.Context, []*dal.Key, []update.Update, ...dal.Precondition) error {
+	return dal.ErrNotSupported
+}
+
+func TestDalgoDB_undeclaredCapabilities(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	t.Setenv(ReportPathEnvVar, reportPath)
+
+	t.Run("run", func(t *testing.T) {
+		TestDalgoDB(t, noUpdateMultiDB{DB: memdb.NewDB()}, errors.New("no query engine"), false)
+	})
+
+	r, err := report.ReadFile(reportPath)
+	require.NoError(t, err)
+	for name, expected := range map[string]report.Outcome{
+		"multi/update_2_records":             report.NotSupported,
+		"multi/cross_collection_transaction": report.Pass,
+		"query":                              report.NotSupported,
+	} {
+		outcome, found := r.Outcome(name)
+		assert.True(t, found, name)
+		assert.Equal(t, expected, outcome, name)
+	}
+	for _, c := range r.Checks {
+		if c.Name == SuiteQuery {
+			assert.Equal(t, "queries: no query engine", c.Reason)
+		}
+	}
+}
+
+func TestDalgoDB_legacySuites(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	t.Setenv(ReportPathEnvVar, reportPath)
+
+	t.Run("run", func(t *testing.T) {
+		TestDalgoDB(t, memdb.NewDB(), nil, false)
+	})
+
+	r, err := report.ReadFile(reportPath)
+	require.NoError(t, err)
+	for _, suite := range legacySuites {
+		_, found := r.Outcome(suite)
+		assert.True(t, found, suite)
+	}
+	// sub-suites added later write to collections that drivers tested by TestDalgoDB may have not provisioned
+	for _, suite := range []string{SuiteUpdate, SuiteTransactions, SuiteContext} {
+		_, found := r.Outcome(suite)
+		assert.False(t, found, suite)
+	}
+}
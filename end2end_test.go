@@ -2,13 +2,16 @@ package end2end
 
 import (
 	"context"
-	"fmt"
-	"slices"
+	"flag"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/dal-go/dalgo-end2end-tests/models"
+	"github.com/dal-go/dalgo-end2end-tests/memdb"
 	"github.com/dal-go/dalgo/dal"
 	"github.com/dal-go/dalgo/mocks/mock_dal"
+	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 )
 
@@ -33,245 +36,110 @@ func TestEndToEnd_panics(t *testing.T) {
 	})
 }
 
-func TestEndToEnd(t *testing.T) {
-	dbCtrl := gomock.NewController(t)
-	defer dbCtrl.Finish()
-
-	var controllers []*gomock.Controller
-
-	db := mock_dal.NewMockDB(dbCtrl)
+// memdbCapabilities declares everything supported by the reference in-memory driver
+var memdbCapabilities = Capabilities{
+	Queries: true,
+	OrderBy: true,
+	WhereOperators: []dal.Operator{
+		dal.Equal,
+		dal.NotEqual,
+		dal.GreaterThen,
+		dal.GreaterOrEqual,
+		dal.LessThen,
+		dal.LessOrEqual,
+		dal.In,
+		dal.ArrayContains,
+	},
+	OrConditions:                true,
+	NestedFields:                true,
+	UpdateMulti:                 true,
+	Increments:                  true,
+	Transactions:                true,
+	CrossCollectionTransactions: true,
+	IncompleteKeys:              true,
+	ParentKeys:                  true,
+}
 
-	keyOnlyRecord := func(collection, id string) dal.Record {
-		return dal.NewRecord(dal.NewKeyWithID(collection, dal.EscapeID(id)))
-	}
+// TestEndToEnd runs the whole suite against the reference in-memory driver, all checks must pass
+func TestEndToEnd(t *testing.T) {
+	TestDalgoDBWithOptions(t, memdb.NewDB(), Options{
+		Capabilities:    memdbCapabilities,
+		Parallel:        true,
+		RandomSequences: 20,
+	})
+}
 
-	var getNumber int
-	db.EXPECT().Get(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, r dal.Record) error {
-		getNumber++
-		switch getNumber {
-		case 1:
-			r.SetError(dal.ErrRecordNotFound)
-			return dal.ErrRecordNotFound
-		case 2:
-			r.SetError(nil)
-			data := r.Data().(*TestData)
-			data.StringProp = "str1"
-			data.IntegerProp = 1
-			r.SetError(nil)
-		}
-		return nil
-	}).Times(2)
+func TestEndToEnd_legacy(t *testing.T) {
+	TestDalgoDB(t, memdb.NewDB(), nil, false)
+}
 
-	var existsCallNumber int
-	db.EXPECT().Exists(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, key *dal.Key) (bool, error) {
-		existsCallNumber++
-		switch existsCallNumber {
-		case 1:
-			return false, nil
-		case 2:
-			return true, nil
-		case 3:
-			return false, nil
-		default:
-			panic("unexpected call number")
-		}
-	}).Times(3)
+// overlapDetectingDB holds the first call until another call starts, so it detects calls made in parallel
+type overlapDetectingDB struct {
+	dal.DB
+	first       sync.Once
+	second      sync.Once
+	started     chan struct{} // closed when a call starts after the first one
+	overlapping atomic.Bool
+}
 
-	readCityIDs := func(cityIDs []string) func(ctx context.Context, query dal.Query) (dal.Reader, error) {
-		return func(ctx context.Context, query dal.Query) (dal.Reader, error) {
-			ctrl := gomock.NewController(t)
-			controllers = append(controllers, ctrl)
-			reader := mock_dal.NewMockRecordsReader(ctrl)
-			i := 0
-			sortedCityIDs := make([]string, len(cityIDs))
-			copy(sortedCityIDs, cityIDs)
-			switch q := query.(type) {
-			case dal.StructuredQuery:
-				if orderBy := q.OrderBy(); len(orderBy) == 1 {
-					if orderBy[0].Descending() {
-						slices.Reverse(sortedCityIDs)
-					}
-				}
-				limit := query.Limit()
-				if citiesCount := len(sortedCityIDs); limit == 0 || limit > citiesCount {
-					limit = citiesCount
-				}
-				reader.EXPECT().Next().DoAndReturn(func() (r dal.Record, err error) {
-					if i >= limit {
-						return nil, dal.ErrNoMoreRecords
-					}
-					r = keyOnlyRecord(models.CitiesCollection, sortedCityIDs[i])
-					i++
-					return
-				}).AnyTimes()
-				reader.EXPECT().Close().Times(1)
-				return reader, nil
-			default:
-				return nil, fmt.Errorf("unexpected query type: %T", query)
+func newOverlapDetectingDB(db dal.DB) *overlapDetectingDB {
+	return &overlapDetectingDB{DB: db, started: make(chan struct{})}
+}
 
-			}
-		}
+func (db *overlapDetectingDB) enter() {
+	isFirst := false
+	db.first.Do(func() {
+		isFirst = true
+	})
+	if !isFirst {
+		db.second.Do(func() {
+			close(db.started)
+		})
+		return
 	}
+	select {
+	case <-db.started:
+		db.overlapping.Store(true)
+	case <-time.After(5 * time.Second): // sequential execution, the next call starts only after this one
+	}
+}
 
-	// Expectation for calls WITHOUT transaction options
-	db.EXPECT().RunReadwriteTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, f dal.RWTxWorker, options ...dal.TransactionOption) error {
-		ctrl := gomock.NewController(t)
-		controllers = append(controllers, ctrl)
-		tx := mock_dal.NewMockReadwriteTransaction(ctrl)
-
-		txOptions := dal.NewTransactionOptions(options...)
-		//tx.EXPECT().Options().Return(txOptions)
-
-		txName := txOptions.Name()
-		//t.Log("RW tx:", txName)
-		switch txName {
-		case "SELECT * FROM Cities: no_limit":
-			tx.EXPECT().GetRecordsReader(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, query dal.Query) (reader dal.RecordsReader, err error) {
-				records := make([]dal.Record, len(models.Cities))
-				for i, city := range models.Cities {
-					key := dal.NewKeyWithID("c1", city)
-					records[i] = dal.NewRecordWithData(key, &city)
-				}
-				return dal.NewRecordsReader(records), nil
-			})
-		case "SELECT * FROM Cities: limit=3":
-			tx.EXPECT().GetRecordsReader(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, query dal.Query) (reader dal.RecordsReader, err error) {
-				records := make([]dal.Record, 3)
-				for i, cityID := range models.SortedCityIDs[:3] {
-					key := dal.NewKeyWithID("c1", cityID)
-					for _, city := range models.Cities {
-						if models.CityID(city) == cityID {
-							records[i] = dal.NewRecordWithData(key, &city)
-							break
-						}
-					}
-				}
-				return dal.NewRecordsReader(records), nil
-			})
-		case "singleDeleteTest":
-			tx.EXPECT().Delete(ctx, gomock.Any()).Return(nil).Times(1)
-		case "deleteAllRecords":
-			tx.EXPECT().DeleteMulti(ctx, gomock.Any()).Return(nil).Times(1)
-		case "deleteAllCities":
-			tx.EXPECT().DeleteMulti(ctx, gomock.Any()).Return(nil).Times(1)
-			tx.EXPECT().GetRecordsReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs(models.SortedCityIDs))
-		case "singleCreateWithPredefinedIDTest":
-			tx.EXPECT().Insert(ctx, gomock.Any()).Return(nil).Times(1)
-		case "setMulti":
-			tx.EXPECT().SetMulti(ctx, gomock.Any()).Return(nil).Times(1)
-		case "update2records":
-			tx.EXPECT().UpdateMulti(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
-		case "setupDataForQueryTests":
-			tx.EXPECT().SetMulti(ctx, gomock.Any()).Return(nil).Times(1)
-		case "":
-			panic("no RW tx name")
-		default:
-			panic("unexpected RW tx name: " + txName)
-		}
-		return f(ctx, tx)
-	}).Times(13)
-
-	db.EXPECT().RunReadonlyTransaction(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, f dal.ROTxWorker, options ...dal.TransactionOption) error {
-		ctrl := gomock.NewController(t)
-		controllers = append(controllers, ctrl)
-		tx := mock_dal.NewMockReadTransaction(ctrl)
-
-		txOptions := dal.NewTransactionOptions(options...)
-		//tx.EXPECT().Options().Return(txOptions)
-
-		txName := txOptions.Name()
-		//t.Log("RO tx:", txName)
-		switch txName {
-		case "SELECT ID FROM Cities; limit=0":
-			tx.EXPECT().GetRecordsReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs(models.SortedCityIDs))
-		case "SELECT ID FROM Cities ORDER BY Population; limit=3":
-			tx.EXPECT().GetRecordsReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs(models.CityIDsSortedByPopulation))
-		case "SELECT ID FROM Cities ORDER BY Population DESCENDING; limit=3":
-			tx.EXPECT().GetRecordsReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs(models.CityIDsSortedByPopulation))
-		case "SELECT ID FROM Cities WHERE Country = 'IN'":
-			tx.EXPECT().GetRecordsReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs([]string{"Delhi_Delhi", "Maharashtra_Mumbai"}))
-		case "verify_cleanupDelete":
-			tx.EXPECT().GetMulti(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, records []dal.Record) error {
-				for _, record := range records {
-					record.SetError(dal.ErrRecordNotFound)
-				}
-				return nil
-			}).Times(1)
-			//tx.EXPECT().QueryReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs(models.SortedCityIDs))
-		case "get3NonExistingRecords":
-			tx.EXPECT().GetMulti(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, records []dal.Record) error {
-				for _, record := range records {
-					record.SetError(dal.ErrRecordNotFound)
-				}
-				return nil
-			}).Times(1)
-		case "using_records_with_data":
-			tx.EXPECT().GetMulti(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, records []dal.Record) error {
-				for _, record := range records {
-					record.SetError(nil)
-					data := record.Data().(*TestData)
-					data.StringProp = record.Key().ID.(string) + "str"
-				}
-				return nil
-			}).Times(1)
-		case "getMulti2existing2missingRecords":
-			tx.EXPECT().GetMulti(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, records []dal.Record) error {
-				r1 := records[0]
-				r1.SetError(nil)
-
-				r2 := records[1]
-				r2.SetError(nil)
-
-				r3 := records[2]
-				r3.SetError(dal.ErrRecordNotFound)
-
-				r4 := records[3]
-				r4.SetError(dal.ErrRecordNotFound)
-
-				return nil
-			}).Times(1)
-		case "getMultiNewRecords":
-			tx.EXPECT().GetMulti(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, records []dal.Record) error {
-				//t.Log("len(records):", len(records))
-				r1 := records[0]
-				r1.SetError(nil)
-				d1 := r1.Data().(*TestData)
-				d1.StringProp = "UpdateD"
+func (db *overlapDetectingDB) Get(ctx context.Context, record dal.Record) error {
+	db.enter()
+	return db.DB.Get(ctx, record)
+}
 
-				r2 := records[1]
-				r2.SetError(nil)
-				d2 := r2.Data().(*TestData)
-				d2.StringProp = "UpdateD"
+func (db *overlapDetectingDB) Exists(ctx context.Context, key *dal.Key) (bool, error) {
+	db.enter()
+	return db.DB.Exists(ctx, key)
+}
 
-				r3 := records[2]
-				r3.SetError(nil)
-				d3 := r3.Data().(*TestData)
-				d3.StringProp = "k2r1str"
+func (db *overlapDetectingDB) GetMulti(ctx context.Context, records []dal.Record) error {
+	db.enter()
+	return db.DB.GetMulti(ctx, records)
+}
 
-				return nil
-			}).Times(1)
-		case "selectAllCities":
-			tx.EXPECT().GetRecordsReader(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, _ dal.Query) (dal.RecordsReader, error) {
-				records := make([]dal.Record, len(models.Cities))
-				for i, city := range models.Cities {
-					key := dal.NewKeyWithID("c1", city)
-					records[i] = dal.NewRecordWithData(key, &city)
-				}
-				return dal.NewRecordsReader(records), nil
-			}).Times(1)
-		case "SELECT ID FROM Cities; limit=3":
-			tx.EXPECT().GetRecordsReader(gomock.Any(), gomock.Any()).DoAndReturn(readCityIDs(models.SortedCityIDs))
-		case "":
-			panic("no RO tx name")
-		default:
-			panic("unexpected RO tx name: " + txName)
-		}
-		return f(ctx, tx)
-	}).AnyTimes()
+func (db *overlapDetectingDB) RunReadonlyTransaction(ctx context.Context, f dal.ROTxWorker, options ...dal.TransactionOption) error {
+	db.enter()
+	return db.DB.RunReadonlyTransaction(ctx, f, options...)
+}
 
-	TestDalgoDB(t, db, nil, true)
+func (db *overlapDetectingDB) RunReadwriteTransaction(ctx context.Context, f dal.RWTxWorker, options ...dal.TransactionOption) error {
+	db.enter()
+	return db.DB.RunReadwriteTransaction(ctx, f, options...)
+}
 
-	for _, ctrl := range controllers {
-		ctrl.Finish()
+func TestDalgoDBWithOptions_parallelSubSuites(t *testing.T) {
+	if parallel := flag.Lookup("test.parallel"); parallel == nil || parallel.Value.String() == "1" {
+		t.Skip("parallel tests are limited to 1 by -test.parallel")
 	}
+	db := newOverlapDetectingDB(memdb.NewDB())
+	t.Run("run", func(t *testing.T) {
+		TestDalgoDBWithOptions(t, db, Options{
+			Capabilities: memdbCapabilities,
+			Parallel:     true,
+			Run:          SuiteSingle + "|" + SuiteMulti,
+		})
+	})
+	assert.True(t, db.overlapping.Load(), "sub-suites should run in parallel")
 }
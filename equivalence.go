@@ -0,0 +1,204 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/models"
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/update"
+)
+
+// TestDalgoDBEquivalence executes the same operations and queries against 2 databases
+// and reports any difference in returned records, their order, existence flags or classification of errors.
+// It helps to find where behaviour of a candidate backend diverges from a reference one, e.g. during a migration.
+// Options apply to both databases: operations that need capabilities not declared are skipped,
+// queries wait for writes to become visible if databases are eventually consistent.
+func TestDalgoDBEquivalence(t *testing.T, reference, candidate dal.DB, options Options) {
+	if t == nil {
+		panic("t == nil")
+	}
+	if reference == nil {
+		panic("reference == nil")
+	}
+	if candidate == nil {
+		panic("candidate == nil")
+	}
+	ctx := context.Background()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		t.Cleanup(cancel)
+	}
+	runID := options.RunID
+	if runID == "" {
+		runID = newRunID()
+	}
+	t.Logf("run ID: %s", runID)
+	collections := newCollectionNames(options.collectionPrefix(), runID)
+	for i, op := range equivalenceOperations(options, collections) {
+		t.Run(fmt.Sprintf("%03d_%s", i+1, op.name), func(t *testing.T) {
+			skipIfNotSupported(t, op.notSupported == "", op.notSupported)
+			skipIfWhereOperatorsNotSupported(t, options.Capabilities, op.whereOperators...)
+			expected := op.run(ctx, reference)
+			actual := op.run(ctx, candidate)
+			if differences := op.diff(expected, actual); len(differences) > 0 {
+				t.Error("candidate diverges from reference:\n" + strings.Join(differences, "\n"))
+			}
+		})
+	}
+	t.Cleanup(func() {
+		for _, db := range []dal.DB{reference, candidate} {
+			deleteRecordsOfCollections(ctx, t, db, options.Capabilities.CrossCollectionTransactions, equivalenceKeys(collections))
+			if options.Capabilities.Queries {
+				if err := deleteAllCities(ctx, db, options, collections.cities); err != nil {
+					t.Errorf("failed to cleanup cities: %v", err)
+				}
+			}
+		}
+	})
+}
+
+// equivalenceKeys are keys of records written by equivalence operations, except cities
+func equivalenceKeys(collections collectionNames) []*dal.Key {
+	return []*dal.Key{
+		dal.NewKeyWithID(collections.kind1, "r0"),
+		dal.NewKeyWithID(collections.kind1, "k1r1"),
+		dal.NewKeyWithID(collections.kind1, "k1r2"),
+		dal.NewKeyWithID(collections.kind2, "k2r1"),
+	}
+}
+
+// equivalenceOperations mirrors operations and queries of single, multi & query suites
+func equivalenceOperations(options Options, collections collectionNames) (operations []operation) {
+	capabilities := options.Capabilities
+	allKeys := equivalenceKeys(collections)
+	r0, k1r1, k1r2, k2r1 := allKeys[0], allKeys[1], allKeys[2], allKeys[3]
+	k1r9 := dal.NewKeyWithID(collections.kind1, "k1r9")
+
+	newRecord := func(key *dal.Key, s string, i int) dal.Record {
+		return dal.NewRecordWithData(key, &TestData{StringProp: s, IntegerProp: i})
+	}
+	// requires marks operations as not supported if a capability they need is not declared
+	requires := func(supported bool, capability string, ops ...operation) []operation {
+		if !supported {
+			for i := range ops {
+				ops[i].notSupported = capability
+			}
+		}
+		return ops
+	}
+	// usesWhereOperators marks an operation to be skipped unless a driver supports the WHERE operators
+	usesWhereOperators := func(op operation, operators ...dal.Operator) operation {
+		op.whereOperators = operators
+		return op
+	}
+	// perCollection writes records of each collection in own transaction unless cross-collection transactions are supported
+	perCollection := func(name string, keys []*dal.Key, write func(ctx context.Context, tx dal.ReadwriteTransaction, keys []*dal.Key) error) []operation {
+		groups := [][]*dal.Key{keys}
+		if !capabilities.CrossCollectionTransactions {
+			groups = keysByCollection(keys)
+		}
+		ops := make([]operation, len(groups))
+		for i, group := range groups {
+			opName := name
+			if len(groups) > 1 {
+				opName = fmt.Sprintf("%s of collection %d", name, i+1)
+			}
+			ops[i] = writeOperation(opName, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				return write(ctx, tx, group)
+			})
+		}
+		return ops
+	}
+	deleteAll := perCollection("DeleteMulti all", allKeys, func(ctx context.Context, tx dal.ReadwriteTransaction, keys []*dal.Key) error {
+		return tx.DeleteMulti(ctx, keys)
+	})
+	recordValues := map[*dal.Key]dal.Record{
+		k1r1: newRecord(k1r1, "k1r1str", 1),
+		k1r2: newRecord(k1r2, "k1r2str", 2),
+		k2r1: newRecord(k2r1, "k2r1str", 3),
+	}
+
+	operations = append(operations, deleteAll...)
+	operations = append(operations,
+		getOperation(r0),
+		existsOperation(r0),
+		writeOperation("Insert r0", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Insert(ctx, newRecord(r0, "str1", 1))
+		}),
+		writeOperation("Insert r0 again", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Insert(ctx, newRecord(r0, "str2", 2))
+		}),
+		existsOperation(r0),
+		getOperation(r0),
+		writeOperation("Set r0", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Set(ctx, newRecord(r0, "str3", 3))
+		}),
+		getOperation(r0),
+		writeOperation("Update r0", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Update(ctx, r0, []update.Update{update.ByFieldName("IntegerProp", 4)})
+		}),
+		getOperation(r0),
+		writeOperation("Update missing record", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Update(ctx, k1r9, []update.Update{update.ByFieldName("IntegerProp", 4)})
+		}),
+		writeOperation("Delete r0", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Delete(ctx, r0)
+		}),
+		existsOperation(r0),
+	)
+	operations = append(operations, perCollection("SetMulti", []*dal.Key{k1r1, k1r2, k2r1}, func(ctx context.Context, tx dal.ReadwriteTransaction, keys []*dal.Key) error {
+		records := make([]dal.Record, len(keys))
+		for i, key := range keys {
+			records[i] = recordValues[key]
+		}
+		return tx.SetMulti(ctx, records)
+	})...)
+	operations = append(operations, getMultiOperation(k1r1, k1r2, k1r9, k2r1))
+	operations = append(operations, requires(capabilities.UpdateMulti, "UpdateMulti",
+		writeOperation("UpdateMulti", func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.UpdateMulti(ctx, []*dal.Key{k1r1, k1r2}, []update.Update{update.ByFieldName("StringProp", "UpdateD")})
+		}),
+	)...)
+	operations = append(operations, getMultiOperation(k1r1, k1r2, k2r1))
+	operations = append(operations, deleteAll...)
+	operations = append(operations, getMultiOperation(allKeys...))
+
+	cities := func() dal.QueryBuilder {
+		return dal.From(dal.NewRootCollectionRef(collections.cities, "")).NewQuery()
+	}
+	newCityRecord := func() dal.Record {
+		return dal.NewRecordWithIncompleteKey(collections.cities, reflect.String, &models.City{})
+	}
+	operations = append(operations, requires(capabilities.Queries, options.queriesCapability(),
+		setupOperation("Set up cities", func(ctx context.Context, db dal.DB) error {
+			return setupDataForQueryTests(ctx, db, options, collections.cities)
+		}),
+		queryOperation("SELECT ID FROM Cities",
+			cities().SelectKeysOnly(reflect.String), false),
+		queryOperation("SELECT * FROM Cities",
+			cities().SelectIntoRecord(newCityRecord), false),
+	)...)
+	operations = append(operations, requires(capabilities.Queries && capabilities.OrderBy, "ORDER BY",
+		queryOperation("SELECT ID FROM Cities ORDER BY Population LIMIT 3",
+			cities().OrderBy(dal.AscendingField("Population")).Limit(3).SelectKeysOnly(reflect.String), true),
+		queryOperation("SELECT ID FROM Cities ORDER BY Population DESC LIMIT 3",
+			cities().OrderBy(dal.DescendingField("Population")).Limit(3).SelectKeysOnly(reflect.String), true),
+	)...)
+	operations = append(operations, requires(capabilities.Queries, options.queriesCapability(),
+		usesWhereOperators(queryOperation("SELECT ID FROM Cities WHERE Country = 'IN'",
+			cities().WhereField("Country", dal.Equal, "IN").SelectKeysOnly(reflect.String), false), dal.Equal),
+	)...)
+	operations = append(operations, requires(capabilities.Queries, options.queriesCapability(),
+		setupOperation("Delete all cities", func(ctx context.Context, db dal.DB) error {
+			return deleteAllCities(ctx, db, options, collections.cities)
+		}),
+		queryOperation("SELECT ID FROM Cities after delete",
+			cities().SelectKeysOnly(reflect.String), false),
+	)...)
+	return operations
+}
@@ -0,0 +1,75 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/memdb"
+	"github.com/dal-go/dalgo/dal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDalgoDBEquivalence_panics(t *testing.T) {
+	assert.Panics(t, func() { TestDalgoDBEquivalence(nil, memdb.NewDB(), memdb.NewDB(), Options{}) })
+	assert.Panics(t, func() { TestDalgoDBEquivalence(t, nil, memdb.NewDB(), Options{}) })
+	assert.Panics(t, func() { TestDalgoDBEquivalence(t, memdb.NewDB(), nil, Options{}) })
+}
+
+func TestDalgoDBEquivalence_sameDriver(t *testing.T) {
+	reference, candidate := memdb.NewDB(), memdb.NewDB()
+	t.Run("run", func(t *testing.T) {
+		TestDalgoDBEquivalence(t, reference, candidate, Options{Capabilities: memdbCapabilities, RunID: "run1"})
+	})
+	collections := newCollectionNames(TestEntitiesNamePrefix, "run1")
+	for _, db := range []dal.DB{reference, candidate} {
+		for _, collection := range []string{collections.kind1, collections.kind2, collections.cities} {
+			records, err := selectAllCities(context.Background(), db, collection)
+			assert.NoError(t, err)
+			assert.Empty(t, records, "records should be deleted by cleanup from "+collection)
+		}
+	}
+}
+
+func TestDalgoDBEquivalence_undeclaredCapabilities(t *testing.T) {
+	// operations needing capabilities not declared are skipped, e.g. queries and cross-collection writes
+	TestDalgoDBEquivalence(t, memdb.NewDB(), memdb.NewDB(), Options{})
+}
+
+// existsAlwaysDB is a broken driver that reports every record as existing
+type existsAlwaysDB struct {
+	dal.DB
+}
+
+func (existsAlwaysDB) Exists(context.Context, *dal.Key) (bool, error) {
+	return true, nil
+}
+
+func TestOperation_diff(t *testing.T) {
+	ctx := context.Background()
+	key := dal.NewKeyWithID(E2ETestKind1, "r1")
+
+	op := existsOperation(key)
+	differences := op.diff(op.run(ctx, memdb.NewDB()), op.run(ctx, existsAlwaysDB{DB: memdb.NewDB()}))
+	assert.Len(t, differences, 1)
+
+	op = getOperation(key)
+	differences = op.diff(op.run(ctx, memdb.NewDB()), op.run(ctx, existsAlwaysDB{DB: memdb.NewDB()}))
+	assert.Empty(t, differences)
+
+	unordered := operation{}
+	a := observation{Records: []observedRecord{{Key: "a"}, {Key: "b"}}}
+	b := observation{Records: []observedRecord{{Key: "b"}, {Key: "a"}}}
+	assert.Empty(t, unordered.diff(a, b))
+	ordered := operation{ordered: true}
+	assert.Len(t, ordered.diff(a, b), 1)
+	assert.Len(t, ordered.diff(a, observation{Records: a.Records, Error: errorNotFound}), 1)
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.Equal(t, noError, classifyError(nil))
+	assert.Equal(t, errorNotFound, classifyError(dal.ErrRecordNotFound))
+	assert.Equal(t, errorExists, classifyError(fmt.Errorf("insert failed: %w", dal.ErrRecordAlreadyExists)))
+	assert.Equal(t, errorUnclassified, classifyError(errors.New("permission denied")))
+}
@@ -0,0 +1,158 @@
+// Package memdb is an in-memory dalgo database.
+// It is a reference implementation that passes all end-to-end checks and serves as an executable spec for drivers.
+package memdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/recordset"
+)
+
+// DatabaseID is returned by ID() of databases created by NewDB
+const DatabaseID = "memdb"
+
+var _ dal.DB = (*database)(nil)
+
+// record data are stored as JSON objects decoded to maps so values are never shared with callers
+type fields = map[string]any
+
+type entry struct {
+	key  *dal.Key
+	data fields
+}
+
+type database struct {
+	mutex   sync.RWMutex // held for the whole duration of a transaction, so transactions are serializable
+	entries map[string]entry
+}
+
+// NewDB creates an empty in-memory database
+func NewDB() dal.DB {
+	return &database{entries: make(map[string]entry)}
+}
+
+func (db *database) ID() string {
+	return DatabaseID
+}
+
+func (db *database) Adapter() dal.Adapter {
+	return dal.NewAdapter("memdb", "v0.0.1")
+}
+
+func (db *database) Schema() dal.Schema {
+	return nil
+}
+
+func (db *database) RunReadonlyTransaction(ctx context.Context, f dal.ROTxWorker, options ...dal.TransactionOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mutex.RLock()
+	defer db.mutex.RUnlock()
+	tx := newTransaction(db, true, options)
+	return f(ctx, tx)
+}
+
+func (db *database) RunReadwriteTransaction(ctx context.Context, f dal.RWTxWorker, options ...dal.TransactionOption) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	db.mutex.Lock()
+	defer db.mutex.Unlock() // also releases the lock if f panics, writes of the transaction are discarded then
+	tx := newTransaction(db, false, options)
+	if err := f(ctx, tx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("transaction is not committed: %w", err)
+	}
+	for k, e := range tx.writes {
+		if e == nil {
+			delete(db.entries, k)
+		} else {
+			db.entries[k] = *e
+		}
+	}
+	return nil
+}
+
+func (db *database) Get(ctx context.Context, record dal.Record) error {
+	return db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+		return tx.Get(ctx, record)
+	})
+}
+
+func (db *database) Exists(ctx context.Context, key *dal.Key) (exists bool, err error) {
+	err = db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+		exists, err = tx.Exists(ctx, key)
+		return err
+	})
+	return
+}
+
+func (db *database) GetMulti(ctx context.Context, records []dal.Record) error {
+	return db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+		return tx.GetMulti(ctx, records)
+	})
+}
+
+func (db *database) ExecuteQueryToRecordsReader(ctx context.Context, query dal.Query) (reader dal.RecordsReader, err error) {
+	err = db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+		reader, err = tx.ExecuteQueryToRecordsReader(ctx, query)
+		return err
+	})
+	return
+}
+
+func (db *database) ExecuteQueryToRecordsetReader(_ context.Context, _ dal.Query, _ ...recordset.Option) (dal.RecordsetReader, error) {
+	return nil, fmt.Errorf("%w: recordset readers", dal.ErrNotSupported)
+}
+
+func (db *database) GetRecordsReader(ctx context.Context, query dal.Query) (dal.RecordsReader, error) {
+	return db.ExecuteQueryToRecordsReader(ctx, query)
+}
+
+func encodeData(key *dal.Key, data any) (fields, error) {
+	if data == nil {
+		return nil, fmt.Errorf("record data is nil, key: %v", key)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode record data, key=%v: %w", key, err)
+	}
+	var f fields
+	if err = json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("record data should be encoded as an object, key=%v: %w", key, err)
+	}
+	return f, nil
+}
+
+func decodeData(key *dal.Key, f fields, target any) error {
+	if target == nil {
+		return nil // e.g. a keys only record
+	}
+	if v := reflect.ValueOf(target); v.Kind() == reflect.Pointer && !v.IsNil() {
+		v.Elem().SetZero() // fields missing in stored data should not keep previous values
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(b, target); err != nil {
+		return fmt.Errorf("failed to decode record data, key=%v: %w", key, err)
+	}
+	return nil
+}
+
+func newErrNotFound(key *dal.Key) error {
+	return fmt.Errorf("%w: %v", dal.ErrRecordNotFound, key)
+}
+
+// ErrReadonlyTransaction is returned on an attempt to write using a read-only transaction
+var ErrReadonlyTransaction = errors.New("writes are not allowed in a read-only transaction")
@@ -0,0 +1,168 @@
+package memdb
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/update"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testData struct {
+	Name  string
+	Count int
+}
+
+func TestDatabase_transactionIsAtomic(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	key := dal.NewKeyWithID("c", "r1")
+	errRollback := errors.New("rollback")
+
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		if err := tx.Set(ctx, dal.NewRecordWithData(key, &testData{Name: "r1"})); err != nil {
+			return err
+		}
+		exists, err := tx.Exists(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, exists, "transaction should see its own writes")
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	exists, err := db.Exists(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, exists, "writes of a failed transaction should be discarded")
+}
+
+func TestDatabase_readonlyTransactionRejectsWrites(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+		return tx.(dal.ReadwriteTransaction).Set(ctx, dal.NewRecordWithData(dal.NewKeyWithID("c", "r1"), &testData{}))
+	})
+	assert.ErrorIs(t, err, ErrReadonlyTransaction)
+}
+
+func TestDatabase_insertGeneratesID(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	records := []dal.Record{
+		dal.NewRecordWithIncompleteKey("c", reflect.String, &testData{Name: "r1"}),
+		dal.NewRecordWithIncompleteKey("c", reflect.String, &testData{Name: "r2"}),
+	}
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.InsertMulti(ctx, records)
+	})
+	require.NoError(t, err)
+	require.NotNil(t, records[0].Key().ID)
+	assert.NotEqual(t, records[0].Key().ID, records[1].Key().ID)
+
+	data := new(testData)
+	require.NoError(t, db.Get(ctx, dal.NewRecordWithData(dal.NewKeyWithID("c", records[0].Key().ID), data)))
+	assert.Equal(t, "r1", data.Name)
+}
+
+func TestDatabase_insertWithIDGenerator(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	record := dal.NewRecordWithIncompleteKey("c", reflect.String, &testData{Name: "r1"})
+	generator := dal.WithIDGenerator(ctx, func(ctx context.Context, record dal.Record) error {
+		record.Key().ID = "generated"
+		return nil
+	})
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Insert(ctx, record, generator)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "generated", record.Key().ID)
+
+	err = db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Insert(ctx, dal.NewRecordWithIncompleteKey("c", reflect.String, &testData{}), generator)
+	})
+	assert.Error(t, err, "generator always returning an existing ID should fail")
+}
+
+func TestDatabase_insertExistingRecord(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	key := dal.NewKeyWithID("c", "r1")
+	insert := func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Insert(ctx, dal.NewRecordWithData(key, &testData{Name: "r1"}))
+	}
+	require.NoError(t, db.RunReadwriteTransaction(ctx, insert))
+	assert.ErrorIs(t, db.RunReadwriteTransaction(ctx, insert), dal.ErrRecordAlreadyExists)
+}
+
+func TestDatabase_query(t *testing.T) {
+	ctx := context.Background()
+	db := NewDB()
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.SetMulti(ctx, []dal.Record{
+			dal.NewRecordWithData(dal.NewKeyWithID("c", "r1"), &testData{Name: "r1", Count: 3}),
+			dal.NewRecordWithData(dal.NewKeyWithID("c", "r2"), &testData{Name: "r2", Count: 1}),
+			dal.NewRecordWithData(dal.NewKeyWithID("c", "r3"), &testData{Name: "r3", Count: 2}),
+			dal.NewRecordWithData(dal.NewKeyWithID("other", "r4"), &testData{Name: "r4", Count: 2}),
+		})
+	})
+	require.NoError(t, err)
+
+	q := dal.From(dal.NewRootCollectionRef("c", "")).NewQuery().
+		WhereField("Count", dal.GreaterThen, 1).
+		OrderBy(dal.DescendingField("Count")).
+		SelectIntoRecord(func() dal.Record {
+			return dal.NewRecordWithIncompleteKey("c", reflect.String, &testData{})
+		})
+	records, err := dal.ExecuteQueryAndReadAllToRecords(ctx, q, db)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "r1", records[0].Key().ID)
+	assert.Equal(t, &testData{Name: "r1", Count: 3}, records[0].Data())
+	assert.Equal(t, "r3", records[1].Key().ID)
+}
+
+func TestApplyUpdates(t *testing.T) {
+	data := fields{"Name": "n", "Count": 1.0, "Nested": fields{"A": "a"}}
+	updated, err := applyUpdates(data, []update.Update{
+		update.ByFieldName("Count", 2),
+		update.ByFieldPath(update.FieldPath{"Nested", "B"}, "b"),
+		update.ByFieldName("Name", update.DeleteField),
+		update.ByFieldName("Count", update.Increment(3)),
+		update.ByFieldName("Missing", update.Increment(1)),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, fields{"Count": 5.0, "Missing": 1.0, "Nested": fields{"A": "a", "B": "b"}}, updated)
+	assert.Equal(t, fields{"Name": "n", "Count": 1.0, "Nested": fields{"A": "a"}}, data, "original data should not change")
+}
+
+func TestCompareCondition(t *testing.T) {
+	data := fields{"Country": "IN", "Languages": []any{"Hindi", "English"}}
+	for _, tt := range []struct {
+		condition dal.Condition
+		expected  bool
+	}{
+		{dal.WhereField("Country", dal.In, []string{"CN", "IN"}), true},
+		{dal.WhereField("Country", dal.In, []string{"CN", "JP"}), false},
+		{dal.WhereField("Languages", dal.ArrayContains, "English"), true},
+		{dal.WhereField("Languages", dal.ArrayContains, "Japanese"), false},
+		{dal.WhereField("Country", dal.ArrayContains, "IN"), false},
+	} {
+		isMatch, err := matches(tt.condition, data)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, isMatch, tt.condition)
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	assert.Equal(t, 0, compareValues(nil, nil))
+	assert.Negative(t, compareValues(nil, false))
+	assert.Negative(t, compareValues(false, true))
+	assert.Negative(t, compareValues(1.0, 2.0))
+	assert.Positive(t, compareValues("b", "a"))
+	assert.Negative(t, compareValues(1.0, "1"))
+	assert.Equal(t, 0, compareValues([]any{"a"}, []any{"a"}))
+}
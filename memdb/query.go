@@ -0,0 +1,255 @@
+package memdb
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/dal-go/dalgo/dal"
+)
+
+// executeQuery returns records of a root collection matching a structured query
+func (tx *transaction) executeQuery(query dal.Query) (records []dal.Record, err error) {
+	q, ok := query.(dal.StructuredQuery)
+	if !ok {
+		return nil, fmt.Errorf("%w: query of type %T", dal.ErrNotSupported, query)
+	}
+	collection := q.From().Base().Name()
+
+	var matched []entry
+	for _, e := range tx.snapshot() {
+		if e.key.Parent() != nil || e.key.Collection() != collection {
+			continue
+		}
+		if where := q.Where(); where != nil {
+			var isMatch bool
+			if isMatch, err = matches(where, e.data); err != nil {
+				return nil, err
+			}
+			if !isMatch {
+				continue
+			}
+		}
+		matched = append(matched, e)
+	}
+
+	if err = sortEntries(matched, q.OrderBy()); err != nil {
+		return nil, err
+	}
+
+	if offset := q.Offset(); offset > 0 {
+		matched = matched[min(offset, len(matched)):]
+	}
+	if limit := q.Limit(); limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+
+	records = make([]dal.Record, len(matched))
+	for i, e := range matched {
+		record := q.IntoRecord()
+		if record == nil { // keys only query
+			record = dal.NewRecord(dal.NewKeyWithID(collection, e.key.ID))
+			record.SetError(nil)
+		} else {
+			record.Key().ID = e.key.ID
+			record.SetError(nil)
+			if err = decodeData(e.key, e.data, record.Data()); err != nil {
+				return nil, err
+			}
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+// snapshot returns records as seen by the transaction ordered by key
+func (tx *transaction) snapshot() (entries []entry) {
+	keys := make([]string, 0, len(tx.db.entries)+len(tx.writes))
+	for k := range tx.db.entries {
+		if _, written := tx.writes[k]; !written {
+			keys = append(keys, k)
+		}
+	}
+	for k, e := range tx.writes {
+		if e != nil {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	entries = make([]entry, len(keys))
+	for i, k := range keys {
+		if e, written := tx.writes[k]; written {
+			entries[i] = *e
+		} else {
+			entries[i] = tx.db.entries[k]
+		}
+	}
+	return entries
+}
+
+func matches(condition dal.Condition, data fields) (bool, error) {
+	switch c := condition.(type) {
+	case dal.GroupCondition:
+		conditions := c.Conditions()
+		switch c.Operator() {
+		case dal.And:
+			for _, nested := range conditions {
+				if isMatch, err := matches(nested, data); err != nil || !isMatch {
+					return false, err
+				}
+			}
+			return true, nil
+		case dal.Or:
+			for _, nested := range conditions {
+				if isMatch, err := matches(nested, data); err != nil || isMatch {
+					return isMatch, err
+				}
+			}
+			return false, nil
+		default:
+			return false, fmt.Errorf("%w: group operator %v", dal.ErrNotSupported, c.Operator())
+		}
+	case dal.Comparison:
+		return compareCondition(c, data)
+	default:
+		return false, fmt.Errorf("%w: condition of type %T", dal.ErrNotSupported, condition)
+	}
+}
+
+func compareCondition(c dal.Comparison, data fields) (bool, error) {
+	field, ok := c.Left.(dal.FieldRef)
+	if !ok {
+		return false, fmt.Errorf("%w: left side of comparison should be a field, got %T", dal.ErrNotSupported, c.Left)
+	}
+	constant, ok := c.Right.(dal.Constant)
+	if !ok {
+		return false, fmt.Errorf("%w: right side of comparison should be a constant, got %T", dal.ErrNotSupported, c.Right)
+	}
+	actual, hasField := data[field.Name()]
+	expected, err := encodeValue(constant.Value)
+	if err != nil {
+		return false, err
+	}
+	switch c.Operator {
+	case dal.Equal:
+		return hasField && compareValues(actual, expected) == 0, nil
+	case dal.NotEqual:
+		return hasField && compareValues(actual, expected) != 0, nil
+	case dal.GreaterThen:
+		return hasField && compareValues(actual, expected) > 0, nil
+	case dal.GreaterOrEqual:
+		return hasField && compareValues(actual, expected) >= 0, nil
+	case dal.LessThen:
+		return hasField && compareValues(actual, expected) < 0, nil
+	case dal.LessOrEqual:
+		return hasField && compareValues(actual, expected) <= 0, nil
+	case dal.In:
+		values, ok := expected.([]any)
+		if !ok {
+			return false, fmt.Errorf("right side of %v should be a slice, got %T", c.Operator, constant.Value)
+		}
+		return hasField && containsValue(values, actual), nil
+	case dal.ArrayContains:
+		values, isArray := actual.([]any)
+		return isArray && containsValue(values, expected), nil
+	default:
+		return false, fmt.Errorf("%w: operator %v", dal.ErrNotSupported, c.Operator)
+	}
+}
+
+func containsValue(values []any, v any) bool {
+	return slices.ContainsFunc(values, func(value any) bool {
+		return compareValues(value, v) == 0
+	})
+}
+
+func sortEntries(entries []entry, orderBy []dal.OrderExpression) error {
+	names := make([]string, len(orderBy))
+	for i, o := range orderBy {
+		field, ok := o.Expression().(dal.FieldRef)
+		if !ok {
+			return fmt.Errorf("%w: order by expression of type %T", dal.ErrNotSupported, o.Expression())
+		}
+		names[i] = field.Name()
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int {
+		for i, name := range names {
+			if result := compareValues(a.data[name], b.data[name]); result != 0 {
+				if orderBy[i].Descending() {
+					return -result
+				}
+				return result
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// encodeValue converts a Go value to a representation used for stored fields, e.g. an int to float64
+func encodeValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var encoded any
+	err = json.Unmarshal(b, &encoded)
+	return encoded, err
+}
+
+// compareValues orders values of stored fields: nil < bool < number < string < others
+func compareValues(a, b any) int {
+	rank := func(v any) int {
+		switch v.(type) {
+		case nil:
+			return 0
+		case bool:
+			return 1
+		case float64:
+			return 2
+		case string:
+			return 3
+		default:
+			return 4
+		}
+	}
+	if ra, rb := rank(a), rank(b); ra != rb {
+		return ra - rb
+	}
+	switch av := a.(type) {
+	case bool:
+		bv := b.(bool)
+		switch {
+		case av == bv:
+			return 0
+		case bv:
+			return -1
+		default:
+			return 1
+		}
+	case float64:
+		bv := b.(float64)
+		switch {
+		case av < bv:
+			return -1
+		case av > bv:
+			return 1
+		default:
+			return 0
+		}
+	case string:
+		return strings.Compare(av, b.(string))
+	case nil:
+		return 0
+	default:
+		if reflect.DeepEqual(a, b) {
+			return 0
+		}
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+}
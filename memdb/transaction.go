@@ -0,0 +1,308 @@
+package memdb
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/recordset"
+	"github.com/dal-go/dalgo/update"
+)
+
+var _ dal.ReadwriteTransaction = (*transaction)(nil)
+
+// transaction buffers writes until committed by database.RunReadwriteTransaction().
+// Read-only transactions are of the same type but reject writes.
+type transaction struct {
+	db       *database
+	id       string
+	readonly bool
+	options  dal.TransactionOptions
+	writes   map[string]*entry // nil entry marks a deleted record
+}
+
+func newTransaction(db *database, readonly bool, options []dal.TransactionOption) *transaction {
+	return &transaction{
+		db:       db,
+		id:       fmt.Sprintf("%016x", rand.Uint64()),
+		readonly: readonly,
+		options:  dal.NewTransactionOptions(options...),
+		writes:   make(map[string]*entry),
+	}
+}
+
+func (tx *transaction) ID() string {
+	return tx.id
+}
+
+func (tx *transaction) Options() dal.TransactionOptions {
+	return tx.options
+}
+
+// lookup returns a record as seen by the transaction, including its own writes
+func (tx *transaction) lookup(key *dal.Key) (e entry, found bool) {
+	k := key.String()
+	if written, ok := tx.writes[k]; ok {
+		if written == nil {
+			return e, false
+		}
+		return *written, true
+	}
+	e, found = tx.db.entries[k]
+	return
+}
+
+func (tx *transaction) Get(ctx context.Context, record dal.Record) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	key := record.Key()
+	e, found := tx.lookup(key)
+	if !found {
+		record.SetError(dal.ErrRecordNotFound)
+		return newErrNotFound(key)
+	}
+	record.SetError(nil)
+	return decodeData(key, e.data, record.Data())
+}
+
+func (tx *transaction) Exists(ctx context.Context, key *dal.Key) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	_, found := tx.lookup(key)
+	return found, nil
+}
+
+func (tx *transaction) GetMulti(ctx context.Context, records []dal.Record) error {
+	for _, record := range records {
+		if err := tx.Get(ctx, record); err != nil && !dal.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *transaction) ExecuteQueryToRecordsReader(ctx context.Context, query dal.Query) (dal.RecordsReader, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	records, err := tx.executeQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	return dal.NewRecordsReader(records), nil
+}
+
+func (tx *transaction) ExecuteQueryToRecordsetReader(ctx context.Context, query dal.Query, options ...recordset.Option) (dal.RecordsetReader, error) {
+	return tx.db.ExecuteQueryToRecordsetReader(ctx, query, options...)
+}
+
+func (tx *transaction) GetRecordsReader(ctx context.Context, query dal.Query) (dal.RecordsReader, error) {
+	return tx.ExecuteQueryToRecordsReader(ctx, query)
+}
+
+// canWrite returns an error if the transaction is read-only or the context is done
+func (tx *transaction) canWrite(ctx context.Context) error {
+	if tx.readonly {
+		return ErrReadonlyTransaction
+	}
+	return ctx.Err()
+}
+
+func (tx *transaction) put(key *dal.Key, data fields) {
+	tx.writes[key.String()] = &entry{key: key, data: data}
+}
+
+func (tx *transaction) Set(ctx context.Context, record dal.Record) error {
+	if err := tx.canWrite(ctx); err != nil {
+		return err
+	}
+	key := record.Key()
+	if key.ID == nil {
+		return fmt.Errorf("record key has no ID, use Insert() for records with incomplete keys: %v", key)
+	}
+	data, err := encodeData(key, record.Data())
+	if err != nil {
+		return err
+	}
+	tx.put(key, data)
+	record.SetError(nil)
+	return nil
+}
+
+func (tx *transaction) SetMulti(ctx context.Context, records []dal.Record) error {
+	for _, record := range records {
+		if err := tx.Set(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert generates an ID for a record with an incomplete key using a generator from insert options if any
+func (tx *transaction) Insert(ctx context.Context, record dal.Record, opts ...dal.InsertOption) error {
+	if err := tx.canWrite(ctx); err != nil {
+		return err
+	}
+	key := record.Key()
+	if key.ID == nil {
+		if err := tx.generateID(ctx, record, dal.NewInsertOptions(opts...).IDGenerator()); err != nil {
+			return err
+		}
+	} else if _, exists := tx.lookup(key); exists {
+		return fmt.Errorf("%w: %v", dal.ErrRecordAlreadyExists, key)
+	}
+	data, err := encodeData(key, record.Data())
+	if err != nil {
+		return err
+	}
+	tx.put(key, data)
+	record.SetError(nil)
+	return nil
+}
+
+// maxIDGenerationAttempts limits how many times an ID generator is called to get an ID not used by existing records
+const maxIDGenerationAttempts = 10
+
+// generateID sets an ID not used by existing records of the same collection to the record's key,
+// IDs are random strings if no generator is passed with insert options
+func (tx *transaction) generateID(ctx context.Context, record dal.Record, generator dal.IDGenerator) error {
+	if generator == nil {
+		generator = randomStringID
+	}
+	key := record.Key()
+	for range maxIDGenerationAttempts {
+		if err := generator(ctx, record); err != nil {
+			return fmt.Errorf("failed to generate ID: %w", err)
+		}
+		if key.ID == nil {
+			return fmt.Errorf("ID generator has not set ID of record key: %v", key)
+		}
+		if _, exists := tx.lookup(key); !exists {
+			return nil
+		}
+	}
+	key.ID = nil
+	return fmt.Errorf("failed to generate unique ID in %d attempts", maxIDGenerationAttempts)
+}
+
+func randomStringID(_ context.Context, record dal.Record) error {
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	var sb strings.Builder
+	for range 16 {
+		sb.WriteByte(alphabet[rand.IntN(len(alphabet))])
+	}
+	record.Key().ID = sb.String()
+	return nil
+}
+
+func (tx *transaction) InsertMulti(ctx context.Context, records []dal.Record, opts ...dal.InsertOption) error {
+	for _, record := range records {
+		if err := tx.Insert(ctx, record, opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *transaction) Update(ctx context.Context, key *dal.Key, updates []update.Update, _ ...dal.Precondition) error {
+	if err := tx.canWrite(ctx); err != nil {
+		return err
+	}
+	e, found := tx.lookup(key)
+	if !found {
+		return newErrNotFound(key)
+	}
+	data, err := applyUpdates(e.data, updates)
+	if err != nil {
+		return fmt.Errorf("failed to update record %v: %w", key, err)
+	}
+	tx.put(key, data)
+	return nil
+}
+
+func (tx *transaction) UpdateRecord(ctx context.Context, record dal.Record, updates []update.Update, preconditions ...dal.Precondition) error {
+	if err := tx.Update(ctx, record.Key(), updates, preconditions...); err != nil {
+		return err
+	}
+	return tx.Get(ctx, record)
+}
+
+func (tx *transaction) UpdateMulti(ctx context.Context, keys []*dal.Key, updates []update.Update, preconditions ...dal.Precondition) error {
+	for _, key := range keys {
+		if err := tx.Update(ctx, key, updates, preconditions...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *transaction) Delete(ctx context.Context, key *dal.Key) error {
+	if err := tx.canWrite(ctx); err != nil {
+		return err
+	}
+	tx.writes[key.String()] = nil
+	return nil
+}
+
+func (tx *transaction) DeleteMulti(ctx context.Context, keys []*dal.Key) error {
+	for _, key := range keys {
+		if err := tx.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyUpdates returns a copy of data with updates applied, nested fields are addressed by field paths
+func applyUpdates(data fields, updates []update.Update) (fields, error) {
+	data = copyFields(data)
+	for _, u := range updates {
+		path := u.FieldPath()
+		if len(path) == 0 {
+			path = update.FieldPath{u.FieldName()}
+		}
+		parent := data
+		for _, name := range path[:len(path)-1] {
+			child, ok := parent[name].(fields)
+			if !ok {
+				child = make(fields)
+				parent[name] = child
+			}
+			parent = child
+		}
+		name := path[len(path)-1]
+		if u.Value() == update.DeleteField {
+			delete(parent, name)
+			continue
+		}
+		if increment, ok := u.Value().(update.IncrementValue); ok {
+			current, isNumber := parent[name].(float64)
+			if !isNumber && parent[name] != nil {
+				return nil, fmt.Errorf("can not increment non-numeric field %v", path)
+			}
+			parent[name] = current + float64(increment.Delta)
+			continue
+		}
+		v, err := encodeValue(u.Value())
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of field %v: %w", path, err)
+		}
+		parent[name] = v
+	}
+	return data, nil
+}
+
+func copyFields(data fields) fields {
+	c := make(fields, len(data))
+	for k, v := range data {
+		if nested, ok := v.(fields); ok {
+			v = copyFields(nested)
+		}
+		c[k] = v
+	}
+	return c
+}
@@ -10,10 +10,13 @@ import (
 const (
 	TestEntitiesNamePrefix = "DalgoE2E_"
 
+	e2eTestKind1Name = "E2ETest1"
+	e2eTestKind2Name = "E2ETest2"
+
 	// E2ETestKind1 defines table or collection name for an entity to be stored in
-	E2ETestKind1 = TestEntitiesNamePrefix + "E2ETest1"
+	E2ETestKind1 = TestEntitiesNamePrefix + e2eTestKind1Name
 	// E2ETestKind2 defines table or collection name for an entity to be stored in
-	E2ETestKind2 = TestEntitiesNamePrefix + "E2ETest2"
+	E2ETestKind2 = TestEntitiesNamePrefix + e2eTestKind2Name
 
 	//UserKind = TestEntitiesNamePrefix + "User"
 )
@@ -29,6 +32,18 @@ type TestData struct {
 	IntegerProp int    `json:"IntegerProp" db:"IntegerProp"`
 }
 
+// TestAddress is a sub-struct of TestNestedData
+type TestAddress struct {
+	City   string `json:"City,omitempty" db:"City"`
+	Street string `json:"Street,omitempty" db:"Street"`
+}
+
+// TestNestedData describes a test entity with embedded and nested structs, used to test updates by field paths
+type TestNestedData struct {
+	TestData
+	Address TestAddress `json:"Address" db:"Address"`
+}
+
 // Validate returns error if not valid
 func (v TestData) Validate() error {
 	if strings.TrimSpace(v.StringProp) == "" {
@@ -139,6 +139,26 @@ var Cities = []City{
 	},
 }
 
+// CityLanguages lists widely spoken languages of a city. It is stored apart from City,
+// so only drivers that support array fields in conditions need to store arrays.
+type CityLanguages struct {
+	Languages []string
+}
+
+// CitiesLanguages are languages of fixture cities by CityID
+var CitiesLanguages = map[string]CityLanguages{
+	"Tokyo_Tokyo":         {Languages: []string{"Japanese"}},
+	"Delhi_Delhi":         {Languages: []string{"Hindi", "English", "Punjabi", "Urdu"}},
+	"Shanghai_Shanghai":   {Languages: []string{"Mandarin", "Shanghainese"}},
+	"São Paulo_São Paulo": {Languages: []string{"Portuguese"}},
+	"Maharashtra_Mumbai":  {Languages: []string{"Marathi", "Hindi", "English"}},
+	"Beijing_Beijing":     {Languages: []string{"Mandarin"}},
+	"Cairo_Cairo":         {Languages: []string{"Arabic"}},
+	"Dhaka_Dhaka":         {Languages: []string{"Bengali", "English"}},
+	"Sindh_Karachi":       {Languages: []string{"Urdu", "Sindhi", "English"}},
+	"Istanbul_Istanbul":   {Languages: []string{"Turkish"}},
+}
+
 var SortedCityIDs []string
 
 var CityIDsSortedByPopulation []string
@@ -97,3 +97,10 @@ func strconvI(i int) string { // covered by TestYear
 	}
 	return string(digits[pos:])
 }
+
+func TestCitiesLanguages(t *testing.T) {
+	require.Len(t, CitiesLanguages, len(Cities))
+	for _, city := range Cities {
+		require.Contains(t, CitiesLanguages, CityID(city))
+	}
+}
@@ -0,0 +1,127 @@
+package end2end
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// ReportPathEnvVar is an environment variable with a path to save a JSON report to, see Options.ReportPath
+const ReportPathEnvVar = "DALGO_E2E_REPORT"
+
+// Names of sub-suites, can be used in Options.Suites and in Options.Run & Options.Skip patterns
+const (
+	SuiteSingle       = "single"
+	SuiteMulti        = "multi"
+	SuiteUpdate       = "update"
+	SuiteTransactions = "transactions"
+	SuiteConcurrency  = "concurrency"
+	SuiteContext      = "context"
+	SuiteQuery        = "query"
+	SuiteProperty     = "property"
+)
+
+// Options defines how TestDalgoDBWithOptions exercises a dalgo driver
+type Options struct {
+	// Capabilities declares features supported by a driver under test
+	Capabilities Capabilities
+
+	// EventuallyConsistent should be true if reads through queries may not immediately reflect writes
+	EventuallyConsistent bool
+
+	// ConsistencyTimeout limits how long an eventually consistent database is polled
+	// for reads to reflect writes, defaults to 10 seconds
+	ConsistencyTimeout time.Duration
+
+	// Timeout limits duration of the whole run, no limit if zero
+	Timeout time.Duration
+
+	// CollectionPrefix is prepended to names of collections created by tests, defaults to TestEntitiesNamePrefix
+	CollectionPrefix string
+
+	// RunID follows CollectionPrefix in names of collections so concurrent runs sharing a database
+	// do not trample each other's data, random if empty
+	RunID string
+
+	// DriverName identifies a driver in a report, defaults to name of the dal.DB adapter
+	DriverName string
+
+	// ReportPath is a file to save a JSON report of the run to, defaults to value of DALGO_E2E_REPORT env var.
+	// No report is saved if both are empty.
+	ReportPath string
+
+	// Parallel runs independent sub-suites in parallel with each other, checks inside a sub-suite stay sequential
+	Parallel bool
+
+	// Suites lists names of sub-suites to run (see SuiteSingle, SuiteMulti, etc.), all if empty
+	Suites []string
+
+	// Run is a pattern selecting checks to run, same as `go test -run` but applied to check names,
+	// e.g. "query" or "multi/GetMulti". All checks run if empty.
+	// Combined with -dalgo.e2e.run flag: a check runs only if it matches both.
+	Run string
+
+	// Skip is a pattern selecting checks not to run, same as `go test -skip` but applied to check names.
+	// Combined with -dalgo.e2e.skip flag: a check is skipped if it matches either.
+	Skip string
+
+	// RandomSequences is a number of random sequences of operations the "property" sub-suite applies
+	// to a driver and to an in-memory model, results must not diverge. The sub-suite is skipped if zero.
+	RandomSequences int
+
+	// RandomSeed makes random sequences reproducible, a random seed is used and logged if zero
+	RandomSeed uint64
+
+	// errQuerySupport is why queries are not supported, as passed to TestDalgoDB
+	errQuerySupport error
+
+	// legacyCollectionNames makes TestDalgoDB use fixed collection names instead of namespaced by a run ID
+	legacyCollectionNames bool
+}
+
+func (o Options) collectionPrefix() string {
+	if o.CollectionPrefix == "" {
+		return TestEntitiesNamePrefix
+	}
+	return o.CollectionPrefix
+}
+
+func (o Options) consistencyTimeout() time.Duration {
+	if o.ConsistencyTimeout == 0 {
+		return defaultConsistencyTimeout
+	}
+	return o.ConsistencyTimeout
+}
+
+func (o Options) checkFilter() (filter checkFilter, err error) {
+	if filter, err = newCheckFilter(o.Run, o.Skip); err != nil {
+		return filter, err
+	}
+	if err = filter.add(*runFlag, *skipFlag); err != nil {
+		return filter, fmt.Errorf("-dalgo.e2e.run or -dalgo.e2e.skip flag: %w", err)
+	}
+	filter.suites = o.Suites
+	return filter, nil
+}
+
+func (o Options) reportPath() string {
+	if o.ReportPath == "" {
+		return os.Getenv(ReportPathEnvVar)
+	}
+	return o.ReportPath
+}
+
+func (o Options) parallel(t *testing.T) {
+	if o.Parallel {
+		t.Parallel()
+	}
+}
+
+// queriesCapability names the queries capability in a skip reason, with an explanation passed to TestDalgoDB if any
+func (o Options) queriesCapability() string {
+	if o.errQuerySupport != nil {
+		return "queries: " + o.errQuerySupport.Error()
+	}
+	return "queries"
+}
@@ -0,0 +1,39 @@
+package end2end
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptions_collectionPrefix(t *testing.T) {
+	assert.Equal(t, TestEntitiesNamePrefix, Options{}.collectionPrefix())
+	assert.Equal(t, "Custom_", Options{CollectionPrefix: "Custom_"}.collectionPrefix())
+}
+
+func TestOptions_checkFilter(t *testing.T) {
+	filter, err := Options{Run: SuiteQuery}.checkFilter()
+	assert.NoError(t, err)
+	assert.True(t, filter.selected(SuiteQuery))
+	assert.False(t, filter.selected(SuiteSingle))
+
+	*runFlag = "query|single"
+	*skipFlag = "query/ORDER BY"
+	t.Cleanup(func() { *runFlag, *skipFlag = "", "" })
+	filter, err = Options{Run: "query|multi", Skip: "query/LIMIT"}.checkFilter()
+	assert.NoError(t, err)
+	assert.True(t, filter.selected(SuiteQuery), "should match both option & flag")
+	assert.False(t, filter.selected(SuiteSingle), "should match option as well as flag")
+	assert.False(t, filter.selected(SuiteMulti), "should match flag as well as option")
+	assert.False(t, filter.selected("query/ORDER BY"), "should be skipped by flag")
+	assert.False(t, filter.selected("query/LIMIT"), "should be skipped by option")
+
+	filter, err = Options{Suites: []string{SuiteQuery, SuiteSingle}, Run: "single|multi"}.checkFilter()
+	assert.NoError(t, err)
+	assert.True(t, filter.selected("single"))
+	assert.False(t, filter.selected("multi"), "should run only listed suites")
+	assert.False(t, filter.selected("query"), "should match run pattern")
+
+	_, err = Options{Skip: "("}.checkFilter()
+	assert.Error(t, err)
+}
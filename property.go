@@ -0,0 +1,142 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/memdb"
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/update"
+)
+
+const (
+	propertyKeySpace       = 4  // small number of keys so operations often hit the same records
+	propertySequenceLength = 30 // operations per random sequence
+)
+
+// propertyOperationsTest applies random sequences of operations to a driver and to the in-memory model,
+// a divergence is reported with a minimal sequence of operations that reproduces it
+func propertyOperationsTest(ctx context.Context, t *testing.T, db dal.DB, options Options, collection string) {
+	if options.RandomSequences <= 0 {
+		t.Skip("no random sequences requested by Options.RandomSequences")
+	}
+	seed := options.RandomSeed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	t.Logf("random seed: %d", seed)
+	random := rand.New(rand.NewPCG(seed, seed))
+
+	keys := make([]*dal.Key, propertyKeySpace)
+	for i := range keys {
+		keys[i] = dal.NewKeyWithID(collection, fmt.Sprintf("k%d", i))
+	}
+	defer func() {
+		if err := resetKeys(ctx, db, keys); err != nil {
+			t.Errorf("failed to cleanup: %v", err)
+		}
+	}()
+
+	for i := 0; i < options.RandomSequences; i++ {
+		operations := randomOperations(random, keys, propertySequenceLength)
+		failedAt, differences, err := checkSequence(ctx, db, keys, operations)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if failedAt < 0 {
+			continue
+		}
+		operations, differences = shrinkSequence(ctx, db, keys, operations[:failedAt+1], differences)
+		names := make([]string, len(operations))
+		for j, op := range operations {
+			names[j] = fmt.Sprintf("%d. %s", j+1, op.name)
+		}
+		t.Fatalf("driver diverges from the model on sequence #%d (seed %d), minimal sequence:\n%s\ndifferences at last operation:\n%s",
+			i+1, seed, strings.Join(names, "\n"), strings.Join(differences, "\n"))
+	}
+}
+
+// checkSequence applies operations to the driver and to a fresh model,
+// it returns index of the first operation with diverging results or -1
+func checkSequence(ctx context.Context, db dal.DB, keys []*dal.Key, operations []operation) (failedAt int, differences []string, err error) {
+	if err = resetKeys(ctx, db, keys); err != nil {
+		return -1, nil, fmt.Errorf("failed to reset records before a sequence: %w", err)
+	}
+	model := memdb.NewDB()
+	for i, op := range operations {
+		if differences = op.diff(op.run(ctx, model), op.run(ctx, db)); len(differences) > 0 {
+			return i, differences, nil
+		}
+	}
+	return -1, nil, nil
+}
+
+// shrinkSequence removes operations one by one while the sequence still fails
+func shrinkSequence(ctx context.Context, db dal.DB, keys []*dal.Key, operations []operation, differences []string) ([]operation, []string) {
+	for i := len(operations) - 2; i >= 0; i-- { // the last operation is the one that diverges
+		candidate := append(operations[:i:i], operations[i+1:]...)
+		failedAt, candidateDifferences, err := checkSequence(ctx, db, keys, candidate)
+		if err != nil || failedAt < 0 {
+			continue
+		}
+		operations, differences = candidate[:failedAt+1], candidateDifferences
+		if i > len(operations)-1 {
+			i = len(operations) - 1
+		}
+	}
+	return operations, differences
+}
+
+func resetKeys(ctx context.Context, db dal.DB, keys []*dal.Key) error {
+	return db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.DeleteMulti(ctx, keys)
+	}, dal.TxWithName("resetKeys"))
+}
+
+func randomOperations(random *rand.Rand, keys []*dal.Key, count int) []operation {
+	randomKey := func() *dal.Key {
+		return keys[random.IntN(len(keys))]
+	}
+	randomData := func() *TestData {
+		i := random.IntN(100)
+		return &TestData{StringProp: fmt.Sprintf("s%d", i), IntegerProp: i}
+	}
+	operations := make([]operation, count)
+	for i := range operations {
+		switch key := randomKey(); random.IntN(7) {
+		case 0:
+			data := randomData()
+			operations[i] = writeOperation(fmt.Sprintf("Insert %v %+v", key.ID, *data), func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				return tx.Insert(ctx, dal.NewRecordWithData(key, data))
+			})
+		case 1:
+			data := randomData()
+			operations[i] = writeOperation(fmt.Sprintf("Set %v %+v", key.ID, *data), func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				return tx.Set(ctx, dal.NewRecordWithData(key, data))
+			})
+		case 2:
+			value := random.IntN(100)
+			operations[i] = writeOperation(fmt.Sprintf("Update %v IntegerProp=%d", key.ID, value), func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				return tx.Update(ctx, key, []update.Update{update.ByFieldName("IntegerProp", value)})
+			})
+		case 3:
+			operations[i] = writeOperation(fmt.Sprintf("Delete %v", key.ID), func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				return tx.Delete(ctx, key)
+			})
+		case 4:
+			operations[i] = getOperation(key)
+		case 5:
+			operations[i] = existsOperation(key)
+		default:
+			subset := make([]*dal.Key, 1+random.IntN(len(keys)))
+			for j := range subset {
+				subset[j] = randomKey()
+			}
+			operations[i] = getMultiOperation(subset...)
+		}
+	}
+	return operations
+}
@@ -0,0 +1,29 @@
+package end2end
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/memdb"
+	"github.com/dal-go/dalgo/dal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShrinkSequence(t *testing.T) {
+	ctx := context.Background()
+	db := existsAlwaysDB{DB: memdb.NewDB()}
+	keys := []*dal.Key{dal.NewKeyWithID("c", "k0"), dal.NewKeyWithID("c", "k1")}
+	operations := randomOperations(rand.New(rand.NewPCG(1, 1)), keys, 50)
+
+	failedAt, differences, err := checkSequence(ctx, db, keys, operations)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, failedAt, 0, "broken driver should diverge from the model")
+
+	operations, differences = shrinkSequence(ctx, db, keys, operations[:failedAt+1], differences)
+	require.Len(t, operations, 1)
+	assert.True(t, strings.HasPrefix(operations[0].name, "Exists "), operations[0].name)
+	assert.NotEmpty(t, differences)
+}
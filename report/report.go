@@ -0,0 +1,103 @@
+// Package report defines a machine-readable outcome of a dalgo end-to-end conformance run
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Outcome of a single check
+type Outcome string
+
+const (
+	// Pass means a check succeeded
+	Pass Outcome = "pass"
+	// Fail means a check failed
+	Fail Outcome = "fail"
+	// Skip means a check was skipped for a reason other than missing driver capability
+	Skip Outcome = "skip"
+	// NotSupported means a check was skipped as a driver does not declare a required capability
+	NotSupported Outcome = "not-supported"
+)
+
+// Check describes outcome of a single check, e.g. "single/get1" or "query/ORDER BY Population/descending"
+type Check struct {
+	Name       string  `json:"name"`
+	Outcome    Outcome `json:"outcome"`
+	DurationMs float64 `json:"durationMs"`
+	Reason     string  `json:"reason,omitempty"`
+}
+
+// Report lists outcomes of all checks executed against a dalgo driver
+type Report struct {
+	Driver       string    `json:"driver"`
+	SuiteVersion string    `json:"suiteVersion"`
+	StartedAt    time.Time `json:"startedAt"`
+	Checks       []Check   `json:"checks"`
+
+	mutex sync.Mutex
+}
+
+// NewReport creates an empty report for a driver
+func NewReport(driver, suiteVersion string) *Report {
+	return &Report{
+		Driver:       driver,
+		SuiteVersion: suiteVersion,
+		StartedAt:    time.Now().UTC(),
+		Checks:       make([]Check, 0),
+	}
+}
+
+// Add records outcome of a check, safe for concurrent use
+func (r *Report) Add(name string, outcome Outcome, duration time.Duration, reason string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.Checks = append(r.Checks, Check{
+		Name:       name,
+		Outcome:    outcome,
+		DurationMs: float64(duration.Microseconds()) / 1000,
+		Reason:     reason,
+	})
+}
+
+// Outcome returns outcome of a check by name
+func (r *Report) Outcome(name string) (outcome Outcome, found bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c.Outcome, true
+		}
+	}
+	return "", false
+}
+
+// WriteFile saves a report as indented JSON
+func WriteFile(path string, r *Report) error {
+	r.mutex.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err = os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write report to %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadFile loads a report saved by WriteFile
+func ReadFile(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	r := new(Report)
+	if err = json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return r, nil
+}
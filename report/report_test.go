@@ -0,0 +1,39 @@
+package report
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAndReadFile(t *testing.T) {
+	r := NewReport("memdb", "0.0.2")
+	r.Add("single/get1", Pass, 1500*time.Microsecond, "")
+	r.Add("query/ORDER BY Population/descending", NotSupported, 0, "ORDER BY")
+
+	path := filepath.Join(t.TempDir(), "report.json")
+	require.NoError(t, WriteFile(path, r))
+
+	loaded, err := ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "memdb", loaded.Driver)
+	assert.Equal(t, "0.0.2", loaded.SuiteVersion)
+	assert.Equal(t, []Check{
+		{Name: "single/get1", Outcome: Pass, DurationMs: 1.5},
+		{Name: "query/ORDER BY Population/descending", Outcome: NotSupported, Reason: "ORDER BY"},
+	}, loaded.Checks)
+
+	outcome, found := loaded.Outcome("single/get1")
+	assert.True(t, found)
+	assert.Equal(t, Pass, outcome)
+	_, found = loaded.Outcome("single/unknown")
+	assert.False(t, found)
+}
+
+func TestReadFile_errors(t *testing.T) {
+	_, err := ReadFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,145 @@
+package end2end
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	runFlag  = flag.String("dalgo.e2e.run", "", "run only dalgo end-to-end checks matching the pattern, combined with Options.Run")
+	skipFlag = flag.String("dalgo.e2e.skip", "", "skip dalgo end-to-end checks matching the pattern, combined with Options.Skip")
+)
+
+// checkFilter selects checks by slash-separated patterns the same way `go test -run` & `-skip` select tests,
+// e.g. "query/ORDER BY" selects checks of the query suite with names containing "ORDER BY".
+// A check runs if its sub-suite is listed in suites (if any), it matches all run patterns and none of skip patterns.
+type checkFilter struct {
+	suites []string
+	run    []pattern
+	skip   []pattern
+}
+
+// pattern is a compiled `go test -run` pattern: alternatives separated by unbracketed '|',
+// each with a regular expression per element of a slash-separated name
+type pattern [][]*regexp.Regexp
+
+func newCheckFilter(run, skip string) (filter checkFilter, err error) {
+	err = filter.add(run, skip)
+	return filter, err
+}
+
+// add narrows the filter by run & skip patterns, an empty pattern does not narrow it
+func (f *checkFilter) add(run, skip string) error {
+	if p, err := compilePattern(run); err != nil {
+		return fmt.Errorf("invalid run pattern: %w", err)
+	} else if p != nil {
+		f.run = append(f.run, p)
+	}
+	if p, err := compilePattern(skip); err != nil {
+		return fmt.Errorf("invalid skip pattern: %w", err)
+	} else if p != nil {
+		f.skip = append(f.skip, p)
+	}
+	return nil
+}
+
+func compilePattern(s string) (p pattern, err error) {
+	if s == "" {
+		return nil, nil
+	}
+	for _, alternative := range splitPattern(s) {
+		elements := make([]*regexp.Regexp, len(alternative))
+		for i, element := range alternative {
+			if elements[i], err = regexp.Compile(element); err != nil {
+				return nil, err
+			}
+		}
+		p = append(p, elements)
+	}
+	return p, nil
+}
+
+// splitPattern splits a pattern into alternatives by '|' and alternatives into elements by '/'
+// skipping separators inside brackets, parentheses or escaped by a backslash, same as `go test -run` does
+func splitPattern(s string) (alternatives [][]string) {
+	var elements []string
+	brackets, parentheses := 0, 0
+	for i := 0; i < len(s); {
+		switch s[i] {
+		case '[':
+			brackets++
+		case ']':
+			if brackets--; brackets < 0 { // an unmatched ']' is legal
+				brackets = 0
+			}
+		case '(':
+			if brackets == 0 {
+				parentheses++
+			}
+		case ')':
+			if brackets == 0 {
+				parentheses--
+			}
+		case '\\':
+			i++
+		case '/', '|':
+			if brackets == 0 && parentheses == 0 {
+				elements = append(elements, s[:i])
+				if s[i] == '|' {
+					alternatives = append(alternatives, elements)
+					elements = nil
+				}
+				s = s[i+1:]
+				i = 0
+				continue
+			}
+		}
+		i++
+	}
+	return append(alternatives, append(elements, s))
+}
+
+// matches checks if a slash-separated name matches the pattern,
+// partially matches if the name has fewer elements than an alternative of the pattern
+func (p pattern) matches(names []string) (ok, partial bool) {
+	for _, alternative := range p {
+		if matchesAll(alternative, names) {
+			if len(names) >= len(alternative) {
+				return true, false
+			}
+			ok, partial = true, true
+		}
+	}
+	return ok, partial
+}
+
+func matchesAll(elements []*regexp.Regexp, names []string) bool {
+	for i, name := range names {
+		if i < len(elements) && !elements[i].MatchString(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// selected checks if a check with a given path (e.g. "single/get1") should run
+func (f checkFilter) selected(path string) bool {
+	names := strings.Split(path, "/")
+	if len(f.suites) > 0 && !slices.Contains(f.suites, names[0]) {
+		return false
+	}
+	for _, p := range f.run {
+		if ok, _ := p.matches(names); !ok {
+			return false
+		}
+	}
+	for _, p := range f.skip {
+		if ok, partial := p.matches(names); ok && !partial {
+			return false
+		}
+	}
+	return true
+}
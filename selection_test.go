@@ -0,0 +1,121 @@
+package end2end
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFilter_selected(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      string
+		skip     string
+		selected map[string]bool
+	}{
+		{
+			name: "empty",
+			selected: map[string]bool{
+				"single":      true,
+				"single/get1": true,
+			},
+		},
+		{
+			name: "run_suite",
+			run:  "^query$",
+			selected: map[string]bool{
+				"query":                      true,
+				"query/SELECT * FROM Cities": true,
+				"single":                     false,
+				"single/get1":                false,
+			},
+		},
+		{
+			name: "run_check",
+			run:  "query/ORDER BY",
+			selected: map[string]bool{
+				"query": true,
+				"query/SELECT ID FROM Cities ORDER BY Population":            true,
+				"query/SELECT ID FROM Cities ORDER BY Population/descending": true,
+				"query/SELECT * FROM Cities":                                 false,
+				"multi":                                                      false,
+			},
+		},
+		{
+			name: "skip_suite",
+			skip: "multi",
+			selected: map[string]bool{
+				"single":         true,
+				"multi":          false,
+				"multi/GetMulti": false,
+			},
+		},
+		{
+			name: "skip_check",
+			skip: "multi/update",
+			selected: map[string]bool{
+				"multi":                  true,
+				"multi/GetMulti":         true,
+				"multi/update_2_records": false,
+				"single/update":          true,
+			},
+		},
+		{
+			name: "run_alternatives",
+			run:  "single/get|multi",
+			selected: map[string]bool{
+				"single":         true,
+				"single/get1":    true,
+				"single/exists1": false,
+				"multi/GetMulti": true,
+				"query":          false,
+			},
+		},
+		{
+			name: "alternatives_inside_parentheses",
+			run:  "query/(ORDER BY|LIMIT)",
+			selected: map[string]bool{
+				"query":                         true,
+				"query/SELECT ID ORDER BY Name": true,
+				"query/SELECT ID LIMIT 3":       true,
+				"query/SELECT * FROM Cities":    false,
+				"single":                        false,
+			},
+		},
+		{
+			name: "run_and_skip",
+			run:  "multi",
+			skip: "multi/update",
+			selected: map[string]bool{
+				"single":                 false,
+				"multi/GetMulti":         true,
+				"multi/update_2_records": false,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newCheckFilter(tt.run, tt.skip)
+			require.NoError(t, err)
+			for path, expected := range tt.selected {
+				assert.Equal(t, expected, filter.selected(path), path)
+			}
+		})
+	}
+}
+
+func TestSplitPattern(t *testing.T) {
+	assert.Equal(t, [][]string{{"a"}}, splitPattern("a"))
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, splitPattern("a/b|c"))
+	assert.Equal(t, [][]string{{"a", "[/|]"}}, splitPattern("a/[/|]"))
+	assert.Equal(t, [][]string{{"(a/b|c)", "d"}}, splitPattern("(a/b|c)/d"))
+	assert.Equal(t, [][]string{{`a\/b`}}, splitPattern(`a\/b`))
+}
+
+func TestNewCheckFilter_invalidPattern(t *testing.T) {
+	_, err := newCheckFilter("query/(", "")
+	assert.Error(t, err)
+	_, err = newCheckFilter("", "[")
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dal-go/dalgo/dal"
+)
+
+// concurrentReadsTimeout limits how long a transaction waits for concurrent transactions to read a record
+// before writing it. Drivers that serialize transactions never let them read concurrently.
+const concurrentReadsTimeout = time.Second
+
+func concurrencyOperationsTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection string) {
+	key := dal.NewKeyWithID(collection, "c1")
+	defer deleteAllRecords(ctx, t, db, []*dal.Key{key})
+
+	check(t, "no_lost_update", func(t *testing.T) {
+		noLostUpdateTest(ctx, t, db, capabilities.ConflictError, key)
+	})
+}
+
+// noLostUpdateTest runs concurrent transactions incrementing the same counter by read-modify-write,
+// each committed transaction must be reflected in the final value
+func noLostUpdateTest(ctx context.Context, t *testing.T, db dal.DB, errConflict error, key *dal.Key) {
+	const concurrency = 2
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Set(ctx, dal.NewRecordWithData(key, &TestData{StringProp: "counter", IntegerProp: 0}))
+	}, dal.TxWithName("noLostUpdateTest setup"))
+	if err != nil {
+		t.Fatalf("failed to set counter: %v", err)
+	}
+
+	var reads sync.WaitGroup
+	reads.Add(concurrency)
+	allRead := make(chan struct{})
+	go func() {
+		reads.Wait()
+		close(allRead)
+	}()
+
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i := range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var readOnce sync.Once
+			defer readOnce.Do(reads.Done) // in case the transaction fails before reading
+			errs[i] = db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				data := new(TestData)
+				if err := tx.Get(ctx, dal.NewRecordWithData(key, data)); err != nil {
+					return err
+				}
+				readOnce.Do(reads.Done) // a retried callback should not count twice
+				select {
+				case <-allRead:
+				case <-time.After(concurrentReadsTimeout):
+				}
+				data.IntegerProp++
+				return tx.Set(ctx, dal.NewRecordWithData(key, data))
+			}, dal.TxWithName(fmt.Sprintf("noLostUpdateTest#%d", i)))
+		}()
+	}
+	wg.Wait()
+
+	committed := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			committed++
+		case errConflict == nil:
+			t.Errorf("transaction #%d failed, conflicting transactions should be serialized or retried"+
+				" unless Capabilities.ConflictError is declared: %v", i, err)
+		case !errors.Is(err, errConflict):
+			t.Errorf("transaction #%d should fail with %v declared by Capabilities.ConflictError, got: %v", i, errConflict, err)
+		}
+	}
+	if committed == 0 {
+		t.Error("at least one of concurrent transactions should commit")
+	}
+
+	data := new(TestData)
+	if err = db.Get(ctx, dal.NewRecordWithData(key, data)); err != nil {
+		t.Fatalf("failed to get counter: %v", err)
+	}
+	if data.IntegerProp != committed {
+		t.Errorf("lost update: %d transactions committed but counter is %d", committed, data.IntegerProp)
+	}
+}
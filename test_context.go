@@ -0,0 +1,100 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dal-go/dalgo/dal"
+)
+
+const (
+	// promptContextErrorTimeout limits how long an operation can take to fail with a done context
+	promptContextErrorTimeout = time.Second
+
+	// transactionDeadline is a deadline of a transaction that outlives it
+	transactionDeadline = 100 * time.Millisecond
+)
+
+func contextOperationsTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection string) {
+	key := dal.NewKeyWithID(collection, "ctx1")
+	deleteAllRecords(ctx, t, db, []*dal.Key{key})
+	defer deleteAllRecords(ctx, t, db, []*dal.Key{key})
+
+	check(t, "canceled", func(t *testing.T) {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		contextDoneTest(ctx, t, db, capabilities, key, canceledCtx, context.Canceled)
+	})
+	check(t, "deadline_exceeded", func(t *testing.T) {
+		expiredCtx, cancel := context.WithTimeout(ctx, time.Nanosecond)
+		defer cancel()
+		<-expiredCtx.Done()
+		contextDoneTest(ctx, t, db, capabilities, key, expiredCtx, context.DeadlineExceeded)
+	})
+	check(t, "deadline_inside_transaction", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Transactions, "transactions")
+		deadlineCtx, cancel := context.WithTimeout(ctx, transactionDeadline)
+		defer cancel()
+		var ctxNotDone bool
+		err := db.RunReadwriteTransaction(deadlineCtx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			if err := tx.Set(ctx, dal.NewRecordWithData(key, &TestData{StringProp: "str1", IntegerProp: 1})); err != nil {
+				return err
+			}
+			select {
+			case <-ctx.Done():
+				ctxNotDone = false
+			case <-time.After(transactionDeadline + promptContextErrorTimeout):
+				ctxNotDone = true // e.g. the transaction context is not derived from the one passed by the caller
+			}
+			return nil
+		}, dal.TxWithName("deadline_inside_transaction"))
+		if ctxNotDone {
+			t.Errorf("context of transaction is not done within %v after deadline of the caller's context", promptContextErrorTimeout)
+		}
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("transaction that outlived its deadline should fail with context.DeadlineExceeded, got: %v", err)
+		}
+		singleExistsTest(ctx, t, db, key, false)
+	})
+}
+
+// contextDoneTest checks operations called with a done context fail promptly with an expected error.
+// The ctx is used to verify nothing was written.
+func contextDoneTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, key *dal.Key, doneCtx context.Context, expected error) {
+	expectContextError := func(t *testing.T, started time.Time, err error) {
+		if elapsed := time.Since(started); elapsed > promptContextErrorTimeout {
+			t.Errorf("operation took %v to fail, expected to fail promptly", elapsed)
+		}
+		if !errors.Is(err, expected) {
+			t.Errorf("expected error compatible with %v, got: %v", expected, err)
+		}
+	}
+	check(t, "Get", func(t *testing.T) {
+		started := time.Now()
+		err := db.Get(doneCtx, dal.NewRecordWithData(key, new(TestData)))
+		expectContextError(t, started, err)
+	})
+	check(t, "GetMulti", func(t *testing.T) {
+		started := time.Now()
+		err := db.GetMulti(doneCtx, []dal.Record{dal.NewRecordWithData(key, new(TestData))})
+		expectContextError(t, started, err)
+	})
+	check(t, "query", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Queries, "queries")
+		q := dal.From(dal.NewRootCollectionRef(key.Collection(), "")).NewQuery().SelectKeysOnly(reflect.String)
+		started := time.Now()
+		_, err := dal.ExecuteQueryAndReadAllToRecords(doneCtx, q, db)
+		expectContextError(t, started, err)
+	})
+	check(t, "transaction", func(t *testing.T) {
+		started := time.Now()
+		err := db.RunReadwriteTransaction(doneCtx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Set(ctx, dal.NewRecordWithData(key, &TestData{StringProp: "str1", IntegerProp: 1}))
+		}, dal.TxWithName("contextDoneTest"))
+		expectContextError(t, started, err)
+		singleExistsTest(ctx, t, db, key, false)
+	})
+}
@@ -19,11 +19,11 @@ func deleteAllRecords(ctx context.Context, t *testing.T, db dal.DB, keys []*dal.
 	}
 }
 
-func multiOperationsTest(ctx context.Context, t *testing.T, db dal.DB) {
+func multiOperationsTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection1, collection2 string) {
 
-	var k1r1Key = dal.NewKeyWithID(E2ETestKind1, "k1r1")
-	var k1r2Key = dal.NewKeyWithID(E2ETestKind1, "k1r2")
-	var k2r1Key = dal.NewKeyWithID(E2ETestKind2, "k2r1")
+	var k1r1Key = dal.NewKeyWithID(collection1, "k1r1")
+	var k1r2Key = dal.NewKeyWithID(collection1, "k1r2")
+	var k2r1Key = dal.NewKeyWithID(collection2, "k2r1")
 
 	var allKeys = []*dal.Key{
 		k1r1Key,
@@ -31,47 +31,93 @@ func multiOperationsTest(ctx context.Context, t *testing.T, db dal.DB) {
 		k2r1Key,
 	}
 
-	t.Run("1st_initial_delete", func(t *testing.T) {
-		deleteAllRecords(ctx, t, db, allKeys)
+	crossCollection := capabilities.CrossCollectionTransactions
+
+	check(t, "1st_initial_delete", func(t *testing.T) {
+		deleteRecordsOfCollections(ctx, t, db, crossCollection, allKeys)
+	})
+	check(t, "2nd_initial_delete", func(t *testing.T) {
+		deleteRecordsOfCollections(ctx, t, db, crossCollection, allKeys)
 	})
-	t.Run("2nd_initial_delete", func(t *testing.T) {
-		deleteAllRecords(ctx, t, db, allKeys)
+	check(t, "get_3_non_existing_records", func(t *testing.T) {
+		get3NonExistingRecords(ctx, t, db)
 	})
-	t.Run("get_3_non_existing_records", func(t *testing.T) {
-		get3NonExistingRecords(t, db)
+	check(t, "SetMulti", func(t *testing.T) {
+		setMulti(ctx, t, db, crossCollection, k1r1Key, k1r2Key, k2r1Key)
 	})
-	t.Run("SetMulti", func(t *testing.T) {
-		setMulti(t, db, k1r1Key, k1r2Key, k2r1Key)
+	check(t, "cross_collection_transaction", func(t *testing.T) {
+		crossCollectionTransactionTest(ctx, t, db, crossCollection, k1r1Key, k2r1Key)
 	})
-	t.Run("GetMulti", func(t *testing.T) {
-		t.Run("3_existing_records", func(t *testing.T) {
-			getMulti3existingRecords(t, allKeys, db)
+	check(t, "GetMulti", func(t *testing.T) {
+		check(t, "3_existing_records", func(t *testing.T) {
+			getMulti3existingRecords(ctx, t, allKeys, db)
 		})
-		t.Run("2_existing_2_missing_records", func(t *testing.T) {
-			getMulti2existing2missingRecords(t, db, k1r1Key, k1r2Key)
+		check(t, "2_existing_2_missing_records", func(t *testing.T) {
+			getMulti2existing2missingRecords(ctx, t, db, k1r1Key, k1r2Key, k2r1Key)
 		})
 	})
-	t.Run("update_2_records", func(t *testing.T) {
-		update2records(t, db, k1r1Key, k1r2Key, k2r1Key)
+	check(t, "update_2_records", func(t *testing.T) {
+		update2records(ctx, t, db, capabilities.UpdateMulti, k1r1Key, k1r2Key, k2r1Key)
 	})
-	t.Run("cleanup_delete", func(t *testing.T) {
-		cleanupDelete(t, db, allKeys)
+	check(t, "cleanup_delete", func(t *testing.T) {
+		cleanupDelete(ctx, t, db, crossCollection, allKeys)
 	})
 }
 
-func getMulti2existing2missingRecords(t *testing.T, db dal.DB, k1r1Key, k1r2Key *dal.Key) {
+// keysByCollection groups keys by collection keeping order of collections
+func keysByCollection(keys []*dal.Key) (groups [][]*dal.Key) {
+	indexes := make(map[string]int)
+	for _, key := range keys {
+		i, ok := indexes[key.Collection()]
+		if !ok {
+			i = len(groups)
+			indexes[key.Collection()] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], key)
+	}
+	return groups
+}
+
+// deleteRecordsOfCollections deletes records in a single transaction if crossCollection is true,
+// otherwise in a transaction per collection
+func deleteRecordsOfCollections(ctx context.Context, t *testing.T, db dal.DB, crossCollection bool, keys []*dal.Key) {
+	if crossCollection {
+		deleteAllRecords(ctx, t, db, keys)
+		return
+	}
+	for _, collectionKeys := range keysByCollection(keys) {
+		deleteAllRecords(ctx, t, db, collectionKeys)
+	}
+}
+
+// crossCollectionTransactionTest overwrites records of 2 collections in one transaction with values set by setMulti
+func crossCollectionTransactionTest(ctx context.Context, t *testing.T, db dal.DB, declared bool, k1Key, k2Key *dal.Key) {
+	records := []dal.Record{
+		dal.NewRecordWithData(k1Key, &TestData{StringProp: fmt.Sprintf("%vstr", k1Key.ID)}),
+		dal.NewRecordWithData(k2Key, &TestData{StringProp: fmt.Sprintf("%vstr", k2Key.ID)}),
+	}
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.SetMulti(ctx, records)
+	}, dal.TxWithName("crossCollectionTransactionTest"))
+	if err != nil {
+		skipIfErrNotSupported(t, declared, err, "cross-collection transactions")
+		t.Fatalf("failed to set records of 2 collections in one transaction: %v", err)
+	}
+}
+
+func getMulti2existing2missingRecords(ctx context.Context, t *testing.T, db dal.DB, k1r1Key, k1r2Key, k2r1Key *dal.Key) {
 	keys := []*dal.Key{
 		k1r1Key,
 		k1r2Key,
-		dal.NewKeyWithID(E2ETestKind1, "k1r9"),
-		dal.NewKeyWithID(E2ETestKind2, "k2r9"),
+		dal.NewKeyWithID(k1r1Key.Collection(), "k1r9"),
+		dal.NewKeyWithID(k2r1Key.Collection(), "k2r9"),
 	}
 	data := make([]TestData, len(keys))
 	records := make([]dal.Record, len(keys))
 	for i, key := range keys {
 		records[i] = dal.NewRecordWithData(key, &data[i])
 	}
-	ctx := context.Background()
 	if err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
 		return tx.GetMulti(ctx, records)
 	}, dal.TxWithName("getMulti2existing2missingRecords")); err != nil {
@@ -101,7 +147,7 @@ func getMulti2existing2missingRecords(t *testing.T, db dal.DB, k1r1Key, k1r2Key
 	}
 }
 
-func get3NonExistingRecords(t *testing.T, db dal.DB) {
+func get3NonExistingRecords(ctx context.Context, t *testing.T, db dal.DB) {
 	records := make([]dal.Record, 3)
 	for i := 0; i < 3; i++ {
 		records[i] = dal.NewRecordWithData(
@@ -109,7 +155,6 @@ func get3NonExistingRecords(t *testing.T, db dal.DB) {
 			&TestData{},
 		)
 	}
-	ctx := context.Background()
 
 	if err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
 		return tx.GetMulti(ctx, records)
@@ -119,7 +164,7 @@ func get3NonExistingRecords(t *testing.T, db dal.DB) {
 	recordsMustNotExist(t, records)
 
 }
-func setMulti(t *testing.T, db dal.DB, k1r1Key, k1r2Key, k2r1Key *dal.Key) {
+func setMulti(ctx context.Context, t *testing.T, db dal.DB, crossCollection bool, k1r1Key, k1r2Key, k2r1Key *dal.Key) {
 	newRecord := func(key *dal.Key) dal.Record {
 		return dal.NewRecordWithData(key, &TestData{
 			StringProp: fmt.Sprintf("%vstr", key.ID),
@@ -130,18 +175,22 @@ func setMulti(t *testing.T, db dal.DB, k1r1Key, k1r2Key, k2r1Key *dal.Key) {
 		newRecord(k1r2Key),
 		newRecord(k2r1Key),
 	}
-	ctx := context.Background()
-	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
-		return tx.SetMulti(ctx, records)
-	}, dal.TxWithName("setMulti"))
-	if err != nil {
-		t.Fatalf("failed to set multiple records at once: %v", err)
+	batches := [][]dal.Record{records}
+	if !crossCollection { // records of the 2nd collection are written by a separate transaction
+		batches = [][]dal.Record{records[:2], records[2:]}
+	}
+	for _, batch := range batches {
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.SetMulti(ctx, batch)
+		}, dal.TxWithName("setMulti"))
+		if err != nil {
+			t.Fatalf("failed to set multiple records at once: %v", err)
+		}
 	}
 }
 
-func cleanupDelete(t *testing.T, db dal.DB, allKeys []*dal.Key) {
-	ctx := context.Background()
-	deleteAllRecords(ctx, t, db, allKeys)
+func cleanupDelete(ctx context.Context, t *testing.T, db dal.DB, crossCollection bool, allKeys []*dal.Key) {
+	deleteRecordsOfCollections(ctx, t, db, crossCollection, allKeys)
 	data := make([]struct{}, len(allKeys))
 	records := make([]dal.Record, len(allKeys))
 	for i := range records {
@@ -155,7 +204,7 @@ func cleanupDelete(t *testing.T, db dal.DB, allKeys []*dal.Key) {
 	recordsMustNotExist(t, records)
 }
 
-func update2records(t *testing.T, db dal.DB, k1r1Key, k1r2Key, k2r1Key *dal.Key) {
+func update2records(ctx context.Context, t *testing.T, db dal.DB, declared bool, k1r1Key, k1r2Key, k2r1Key *dal.Key) {
 	const newValue = "UpdateD"
 	updates := []update.Update{
 		update.ByFieldName("StringProp", newValue),
@@ -171,15 +220,11 @@ func update2records(t *testing.T, db dal.DB, k1r1Key, k1r2Key, k2r1Key *dal.Key)
 			dal.NewRecordWithData(k2r1Key, data[2]),
 		}
 	}
-	ctx := context.Background()
 	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
 		return tx.UpdateMulti(ctx, []*dal.Key{k1r1Key, k1r2Key}, updates)
 	}, dal.TxWithName("update2records"))
 	if err != nil {
-		if errors.Is(err, dal.ErrNotSupported) {
-			t.Log(err)
-			return
-		}
+		skipIfErrNotSupported(t, declared, err, "UpdateMulti")
 		t.Fatalf("failed to update 2 records at once: %v", err)
 	}
 	records := newRecords()
@@ -232,7 +277,7 @@ func recordsMustNotExist(t *testing.T, records []dal.Record) (hasError bool) {
 	return hasError
 }
 
-func getMulti3existingRecords(t *testing.T, allKeys []*dal.Key, db dal.DB) {
+func getMulti3existingRecords(ctx context.Context, t *testing.T, allKeys []*dal.Key, db dal.DB) {
 	var data []TestData
 	records := make([]dal.Record, len(allKeys))
 	assetProps := func(t *testing.T) {
@@ -249,12 +294,11 @@ func getMulti3existingRecords(t *testing.T, allKeys []*dal.Key, db dal.DB) {
 			assertStringProp(i, record)
 		}
 	}
-	t.Run("using_records_with_data", func(t *testing.T) {
+	check(t, "using_records_with_data", func(t *testing.T) {
 		data = make([]TestData, len(allKeys))
 		for i := range records {
 			records[i] = dal.NewRecordWithData(allKeys[i], &data[i])
 		}
-		ctx := context.Background()
 		if err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
 			return tx.GetMulti(ctx, records)
 		}, dal.TxWithName("using_records_with_data")); err != nil {
@@ -265,7 +309,7 @@ func getMulti3existingRecords(t *testing.T, allKeys []*dal.Key, db dal.DB) {
 		}
 		assetProps(t)
 	})
-	//t.Run("using_DataTo", func(t *testing.T) {
+	//check(t, "using_DataTo", func(t *testing.T) {
 	//	for i := range records {
 	//		records[i] = dal.NewRecord(allKeys[i])
 	//	}
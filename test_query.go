@@ -4,18 +4,18 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"sort"
 	"testing"
-	"time"
 
 	"github.com/dal-go/dalgo-end2end-tests/models"
 	"github.com/dal-go/dalgo/dal"
 	"github.com/stretchr/testify/assert"
 )
 
-func selectAllCities(ctx context.Context, db dal.DB) (records []dal.Record, err error) {
-	q := dal.From(dal.NewRootCollectionRef(models.CitiesCollection, "")).NewQuery().SelectIntoRecord(func() dal.Record {
-		return dal.NewRecordWithIncompleteKey(models.CitiesCollection, reflect.String, &models.City{})
+func selectAllCities(ctx context.Context, db dal.DB, collection string) (records []dal.Record, err error) {
+	q := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery().SelectIntoRecord(func() dal.Record {
+		return dal.NewRecordWithIncompleteKey(collection, reflect.String, &models.City{})
 	})
 	err = db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
 		records, err = dal.ExecuteQueryAndReadAllToRecords(ctx, q, tx)
@@ -24,29 +24,42 @@ func selectAllCities(ctx context.Context, db dal.DB) (records []dal.Record, err
 	return
 }
 
-func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuallyConsistent bool) {
+// allCitiesAreVisible returns error if a query does not return exactly fixture cities,
+// e.g. some are not visible yet or previously deleted records are still there
+func allCitiesAreVisible(ctx context.Context, db dal.DB, collection string) error {
+	records, err := selectAllCities(ctx, db, collection)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(records))
+	for i, record := range records {
+		ids[i] = fmt.Sprint(record.Key().ID)
+	}
+	sort.Strings(ids)
+	if !slices.Equal(ids, models.SortedCityIDs) {
+		return fmt.Errorf("expected cities %v, got %v", models.SortedCityIDs, ids)
+	}
+	return nil
+}
+
+// queryOperationsTest queries fixture cities stored in collection,
+// languages of the cities are stored in languagesCollection only for checks of array fields
+func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, options Options, collection, languagesCollection string) {
 	defer func() { // Cleanup after test
-		if err := deleteAllCities(ctx, db); err != nil {
+		if err := deleteAllCities(ctx, db, options, collection); err != nil {
 			t.Fatalf("unexpected error while deleting test data: %v", err)
 		}
 	}()
-	if err := setupDataForQueryTests(ctx, db); err != nil {
+	if err := setupDataForQueryTests(ctx, db, options, collection); err != nil {
 		t.Fatalf("unexpected error while setting up test data: %v", err)
 	}
 
-	if eventuallyConsistent { // This is to work around eventual consistency
-		time.Sleep(1 * time.Second)
-		if _, err := selectAllCities(ctx, db); err != nil {
-			t.Fatalf("unexpected error while loading all cities: %v", err)
-		}
-	}
-
 	var newCityRecord = func() dal.Record {
-		return dal.NewRecordWithIncompleteKey(models.CitiesCollection, reflect.String, &models.City{})
+		return dal.NewRecordWithIncompleteKey(collection, reflect.String, &models.City{})
 	}
-	t.Run(`SELECT ID FROM Cities`, func(t *testing.T) {
-		qb := dal.From(dal.NewRootCollectionRef(models.CitiesCollection, "")).NewQuery()
-		t.Run("no_limit", func(t *testing.T) {
+	check(t, `SELECT ID FROM Cities`, func(t *testing.T) {
+		qb := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery()
+		check(t, "no_limit", func(t *testing.T) {
 			q := qb.SelectKeysOnly(reflect.String)
 			if q == nil {
 				t.Fatalf("query is nil")
@@ -72,7 +85,7 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 			}, dal.TxWithName("SELECT ID FROM Cities; limit=0"))
 			assert.Nil(t, err)
 		})
-		t.Run("limit=3", func(t *testing.T) {
+		check(t, "limit=3", func(t *testing.T) {
 			q := qb.Limit(3).SelectKeysOnly(reflect.String)
 			err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
 				reader, err := tx.ExecuteQueryToRecordsReader(ctx, q)
@@ -96,9 +109,9 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 			assert.Nil(t, err)
 		})
 	})
-	t.Run(`SELECT * FROM Cities`, func(t *testing.T) {
-		qb := dal.From(dal.NewRootCollectionRef(models.CitiesCollection, "")).NewQuery()
-		t.Run("no_limit", func(t *testing.T) {
+	check(t, `SELECT * FROM Cities`, func(t *testing.T) {
+		qb := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery()
+		check(t, "no_limit", func(t *testing.T) {
 			query2 := qb.SelectIntoRecord(newCityRecord)
 			err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
 				records, err := dal.ExecuteQueryAndReadAllToRecords(ctx, query2, tx)
@@ -110,7 +123,7 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 			}, dal.TxWithName("SELECT * FROM Cities: no_limit"))
 			assert.Nil(t, err)
 		})
-		t.Run("limit=3", func(t *testing.T) {
+		check(t, "limit=3", func(t *testing.T) {
 			q := qb.Limit(3).SelectIntoRecord(newCityRecord)
 			err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
 				records, err := dal.ExecuteQueryAndReadAllToRecords(ctx, q, tx)
@@ -123,9 +136,10 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 			assert.Nil(t, err)
 		})
 	})
-	t.Run("SELECT ID FROM Cities ORDER BY Population", func(t *testing.T) {
-		qb := dal.From(dal.NewRootCollectionRef(models.CitiesCollection, ""))
-		t.Run("ascending", func(t *testing.T) {
+	check(t, "SELECT ID FROM Cities ORDER BY Population", func(t *testing.T) {
+		skipIfNotSupported(t, options.Capabilities.OrderBy, "ORDER BY")
+		qb := dal.From(dal.NewRootCollectionRef(collection, ""))
+		check(t, "ascending", func(t *testing.T) {
 			q := qb.NewQuery().
 				OrderBy(dal.AscendingField("Population")).
 				Limit(3).
@@ -152,7 +166,7 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 			}, dal.TxWithName("SELECT ID FROM Cities ORDER BY Population; limit=3"))
 			assert.Nil(t, err)
 		})
-		t.Run("descending", func(t *testing.T) {
+		check(t, "descending", func(t *testing.T) {
 			q := qb.NewQuery().
 				OrderBy(dal.DescendingField("Population")).
 				Limit(3).
@@ -181,9 +195,10 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 
 		})
 	})
-	t.Run("SELECT_ID_FROM_Cities_WHERE_Country_=_'IN'", func(t *testing.T) {
-		qb := dal.From(dal.NewRootCollectionRef(models.CitiesCollection, "")).NewQuery()
-		t.Run("no_limit", func(t *testing.T) {
+	check(t, "SELECT_ID_FROM_Cities_WHERE_Country_=_'IN'", func(t *testing.T) {
+		skipIfWhereOperatorsNotSupported(t, options.Capabilities, dal.Equal)
+		qb := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery()
+		check(t, "no_limit", func(t *testing.T) {
 			q := qb.WhereField("Country", dal.Equal, "IN").SelectKeysOnly(reflect.String)
 			err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
 				reader, err := tx.ExecuteQueryToRecordsReader(ctx, q)
@@ -209,11 +224,33 @@ func queryOperationsTest(ctx context.Context, t *testing.T, db dal.DB, eventuall
 
 		})
 	})
+	check(t, "SELECT_ID_FROM_Cities_WHERE_comparison", func(t *testing.T) {
+		queryWhereComparisonTest(ctx, t, db, options.Capabilities, collection)
+	})
+	check(t, "SELECT_ID_FROM_Cities_WHERE_AND_OR", func(t *testing.T) {
+		queryWhereGroupTest(ctx, t, db, options.Capabilities, collection)
+	})
+	check(t, "SELECT_ID_FROM_Cities_WHERE_membership", func(t *testing.T) {
+		queryWhereMembershipTest(ctx, t, db, options, collection, languagesCollection)
+	})
 }
 
-func deleteAllCities(ctx context.Context, db dal.DB) (err error) {
+// deleteAllCities deletes cities returned by a query,
+// for an eventually consistent database it repeats until the query returns none
+func deleteAllCities(ctx context.Context, db dal.DB, options Options, collection string) error {
+	return options.whenConsistent(ctx, func(ctx context.Context) error {
+		deleted, err := deleteVisibleCities(ctx, db, collection)
+		if err == nil && deleted > 0 && options.EventuallyConsistent {
+			err = fmt.Errorf("%d cities have been visible to a query before deletion", deleted)
+		}
+		return err
+	})
+}
+
+// deleteVisibleCities deletes cities returned by a query and returns their number
+func deleteVisibleCities(ctx context.Context, db dal.DB, collection string) (deleted int, err error) {
 	err = db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
-		q := dal.From(dal.NewRootCollectionRef(models.CitiesCollection, "")).NewQuery().Limit(1000).SelectKeysOnly(reflect.String)
+		q := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery().Limit(1000).SelectKeysOnly(reflect.String)
 		var reader dal.RecordsReader
 		if reader, err = tx.ExecuteQueryToRecordsReader(ctx, q); err != nil {
 			return fmt.Errorf("failed to query all cities: %w", err)
@@ -227,31 +264,42 @@ func deleteAllCities(ctx context.Context, db dal.DB) (err error) {
 		}
 		keys := make([]*dal.Key, len(ids))
 		for i, id := range ids {
-			keys[i] = dal.NewKeyWithID(models.CitiesCollection, id)
+			keys[i] = dal.NewKeyWithID(collection, id)
 		}
+		deleted = len(ids)
 		if len(ids) == 0 {
 			return nil
 		}
 		return tx.DeleteMulti(ctx, keys)
 	}, dal.TxWithName("deleteAllCities"))
 	if err != nil {
-		return fmt.Errorf("failed to delete all cities: %w", err)
+		return 0, fmt.Errorf("failed to delete all cities: %w", err)
 	}
-	return nil
+	return deleted, nil
 }
 
-func setupDataForQueryTests(ctx context.Context, db dal.DB) (err error) {
-	if err := deleteAllCities(ctx, db); err != nil {
+// setupDataForQueryTests replaces records of a collection with fixture cities and waits for queries to return them
+func setupDataForQueryTests(ctx context.Context, db dal.DB, options Options, collection string) (err error) {
+	if err := deleteAllCities(ctx, db, options, collection); err != nil {
 		return err
 	}
-	return db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+	err = db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
 		records := make([]dal.Record, len(models.Cities))
 		for i := range models.Cities { // Do not use value `for _, city` variable as all record will have same pointer to last city
 			records[i] = dal.NewRecordWithData(
-				dal.NewKeyWithID(models.CitiesCollection, models.CityID(models.Cities[i])),
+				dal.NewKeyWithID(collection, models.CityID(models.Cities[i])),
 				&models.Cities[i],
 			)
 		}
 		return tx.SetMulti(ctx, records)
 	}, dal.TxWithName("setupDataForQueryTests"))
+	if err != nil {
+		return err
+	}
+	if err = options.whenConsistent(ctx, func(ctx context.Context) error {
+		return allCitiesAreVisible(ctx, db, collection)
+	}); err != nil {
+		return fmt.Errorf("test data is not visible to queries: %w", err)
+	}
+	return nil
 }
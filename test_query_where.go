@@ -0,0 +1,189 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/models"
+	"github.com/dal-go/dalgo/dal"
+	"github.com/stretchr/testify/assert"
+)
+
+// citiesWhere returns sorted IDs of fixture cities matching a filter, as expected to be returned by a query
+func citiesWhere(filter func(city models.City) bool) []string {
+	ids := make([]string, 0, len(models.Cities))
+	for _, city := range models.Cities {
+		if filter(city) {
+			ids = append(ids, dal.EscapeID(models.CityID(city)))
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// selectCityIDs executes a keys only query and returns sorted IDs
+func selectCityIDs(ctx context.Context, db dal.DB, q dal.Query) (ids []string, err error) {
+	err = db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+		reader, err := tx.ExecuteQueryToRecordsReader(ctx, q)
+		if err != nil {
+			return err
+		}
+		ids, err = dal.SelectAllIDs[string](ctx, reader)
+		return err
+	}, dal.TxWithName(fmt.Sprint(q)))
+	slices.Sort(ids)
+	return ids, err
+}
+
+// whereCityIDsTest checks a query with a condition returns expected IDs of fixture cities
+func whereCityIDsTest(ctx context.Context, t *testing.T, db dal.DB, collection string, condition dal.Condition, expected []string) {
+	q := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery().
+		Where(condition).
+		SelectKeysOnly(reflect.String)
+	ids, err := selectCityIDs(ctx, db, q)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	assert.ElementsMatch(t, expected, ids)
+}
+
+func queryWhereComparisonTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection string) {
+	fields := []struct {
+		name  string
+		value func(city models.City) int
+	}{
+		{"Population", func(city models.City) int { return city.Population }},
+		{"AreaSqKm", func(city models.City) int { return city.AreaSqKm }},
+	}
+	operators := []struct {
+		operator dal.Operator
+		compare  func(a, b int) bool
+	}{
+		{dal.GreaterThen, func(a, b int) bool { return a > b }},
+		{dal.GreaterOrEqual, func(a, b int) bool { return a >= b }},
+		{dal.LessThen, func(a, b int) bool { return a < b }},
+		{dal.LessOrEqual, func(a, b int) bool { return a <= b }},
+		{dal.NotEqual, func(a, b int) bool { return a != b }},
+	}
+	for _, field := range fields {
+		pivotValue := medianValue(field.value)
+		for _, o := range operators {
+			check(t, fmt.Sprintf("%s_%s_median", field.name, o.operator), func(t *testing.T) {
+				skipIfWhereOperatorsNotSupported(t, capabilities, o.operator)
+				t.Logf("median of %s: %d", field.name, pivotValue)
+				whereCityIDsTest(ctx, t, db, collection, dal.WhereField(field.name, o.operator, pivotValue), citiesWhere(func(city models.City) bool {
+					return o.compare(field.value(city), pivotValue)
+				}))
+			})
+		}
+	}
+}
+
+// medianValue returns a median of a field of fixture cities, comparisons with it select neither none nor all cities.
+// It is a value of a fixture city, so operators including equality select different cities than those excluding it.
+func medianValue(value func(city models.City) int) int {
+	values := make([]int, len(models.Cities))
+	for i, city := range models.Cities {
+		values[i] = value(city)
+	}
+	slices.Sort(values)
+	return values[len(values)/2]
+}
+
+func queryWhereGroupTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection string) {
+	const population = 25_000_000
+	check(t, "Country_==_IN_AND_Population_>_25000000", func(t *testing.T) {
+		skipIfWhereOperatorsNotSupported(t, capabilities, dal.Equal, dal.GreaterThen)
+		condition := dal.NewGroupCondition(dal.And,
+			dal.WhereField("Country", dal.Equal, "IN"),
+			dal.WhereField("Population", dal.GreaterThen, population),
+		)
+		whereCityIDsTest(ctx, t, db, collection, condition, citiesWhere(func(city models.City) bool {
+			return city.Country == "IN" && city.Population > population
+		}))
+	})
+	check(t, "IsCapital_==_true_OR_HasAirport_==_false", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.OrConditions, "OR conditions")
+		skipIfWhereOperatorsNotSupported(t, capabilities, dal.Equal)
+		condition := dal.NewGroupCondition(dal.Or,
+			dal.WhereField("IsCapital", dal.Equal, true),
+			dal.WhereField("HasAirport", dal.Equal, false),
+		)
+		whereCityIDsTest(ctx, t, db, collection, condition, citiesWhere(func(city models.City) bool {
+			return city.IsCapital || !city.HasAirport
+		}))
+	})
+	check(t, "(Country_==_IN_OR_Country_==_CN)_AND_Population_>_25000000", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.OrConditions, "OR conditions")
+		skipIfWhereOperatorsNotSupported(t, capabilities, dal.Equal, dal.GreaterThen)
+		condition := dal.NewGroupCondition(dal.And,
+			dal.NewGroupCondition(dal.Or,
+				dal.WhereField("Country", dal.Equal, "IN"),
+				dal.WhereField("Country", dal.Equal, "CN"),
+			),
+			dal.WhereField("Population", dal.GreaterThen, population),
+		)
+		whereCityIDsTest(ctx, t, db, collection, condition, citiesWhere(func(city models.City) bool {
+			return (city.Country == "IN" || city.Country == "CN") && city.Population > population
+		}))
+	})
+}
+
+func queryWhereMembershipTest(ctx context.Context, t *testing.T, db dal.DB, options Options, collection, languagesCollection string) {
+	capabilities := options.Capabilities
+	check(t, "Country_In_CN_IN_JP", func(t *testing.T) {
+		skipIfWhereOperatorsNotSupported(t, capabilities, dal.In)
+		countries := []string{"CN", "IN", "JP"}
+		whereCityIDsTest(ctx, t, db, collection, dal.WhereField("Country", dal.In, countries), citiesWhere(func(city models.City) bool {
+			return slices.Contains(countries, city.Country)
+		}))
+	})
+	check(t, "Languages_array-contains_English", func(t *testing.T) {
+		skipIfWhereOperatorsNotSupported(t, capabilities, dal.ArrayContains)
+		keys := setupCityLanguages(ctx, t, db, options, languagesCollection)
+		defer deleteAllRecords(ctx, t, db, keys)
+		var expected []string
+		for id, languages := range models.CitiesLanguages {
+			if slices.Contains(languages.Languages, "English") {
+				expected = append(expected, dal.EscapeID(id))
+			}
+		}
+		whereCityIDsTest(ctx, t, db, languagesCollection, dal.WhereField("Languages", dal.ArrayContains, "English"), expected)
+	})
+}
+
+// setupCityLanguages stores languages of fixture cities and waits for queries to return them
+func setupCityLanguages(ctx context.Context, t *testing.T, db dal.DB, options Options, collection string) (keys []*dal.Key) {
+	records := make([]dal.Record, 0, len(models.CitiesLanguages))
+	expected := make([]string, 0, len(models.CitiesLanguages))
+	for id, languages := range models.CitiesLanguages {
+		key := dal.NewKeyWithID(collection, id)
+		keys = append(keys, key)
+		records = append(records, dal.NewRecordWithData(key, &languages))
+		expected = append(expected, dal.EscapeID(id))
+	}
+	slices.Sort(expected)
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.SetMulti(ctx, records)
+	}, dal.TxWithName("setupCityLanguages"))
+	if err != nil {
+		t.Fatalf("failed to set up languages of cities: %v", err)
+	}
+	q := dal.From(dal.NewRootCollectionRef(collection, "")).NewQuery().SelectKeysOnly(reflect.String)
+	if err = options.whenConsistent(ctx, func(ctx context.Context) error {
+		ids, err := selectCityIDs(ctx, db, q)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(ids, expected) {
+			return fmt.Errorf("expected languages of cities %v, got %v", expected, ids)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("languages of cities are not visible to queries: %v", err)
+	}
+	return keys
+}
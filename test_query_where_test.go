@@ -0,0 +1,26 @@
+package end2end
+
+import (
+	"testing"
+
+	"github.com/dal-go/dalgo-end2end-tests/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedianValue(t *testing.T) {
+	for name, value := range map[string]func(city models.City) int{
+		"Population": func(city models.City) int { return city.Population },
+		"AreaSqKm":   func(city models.City) int { return city.AreaSqKm },
+	} {
+		median := medianValue(value)
+		for operator, filter := range map[string]func(city models.City) bool{
+			">":  func(city models.City) bool { return value(city) > median },
+			"<":  func(city models.City) bool { return value(city) < median },
+			"!=": func(city models.City) bool { return value(city) != median },
+		} {
+			ids := citiesWhere(filter)
+			assert.NotEmpty(t, ids, "%s %s %d", name, operator, median)
+			assert.Less(t, len(ids), len(models.Cities), "%s %s %d", name, operator, median)
+		}
+	}
+}
@@ -2,64 +2,154 @@ package end2end
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/update"
 )
 
-func singleOperationsTest(ctx context.Context, t *testing.T, db dal.DB) {
-	t.Run("single", func(t *testing.T) {
+func singleOperationsTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection string) {
+	check(t, "single", func(t *testing.T) { // the nested name keeps paths like single/single/get1 of earlier versions
 		const id = "r0"
-		key := dal.NewKeyWithID(E2ETestKind1, id)
-		if !t.Run("delete1", func(t *testing.T) {
-			singleDeleteTest(t, db, key)
+		key := dal.NewKeyWithID(collection, id)
+		if !check(t, "delete1", func(t *testing.T) {
+			singleDeleteTest(ctx, t, db, key)
 		}) {
 			return
 		}
-		if !t.Run("get1", func(t *testing.T) {
+		if !check(t, "get1", func(t *testing.T) {
 			singleGetTest(ctx, t, db, key, false)
 		}) {
 			return
 		}
-		if !t.Run("exists1", func(t *testing.T) {
+		if !check(t, "exists1", func(t *testing.T) {
 			singleExistsTest(ctx, t, db, key, false)
 		}) {
 			return
 		}
-		if !t.Run("create", func(t *testing.T) {
-			if !t.Run("with_predefined_id", func(t *testing.T) {
+		if !check(t, "create", func(t *testing.T) {
+			if !check(t, "with_predefined_id", func(t *testing.T) {
 				singleCreateWithPredefinedIDTest(ctx, t, db, key)
 			}) {
 				t.Error("failed in sub-test")
 			}
+			if !check(t, "duplicate_id", func(t *testing.T) {
+				singleCreateWithDuplicateIDTest(ctx, t, db, capabilities.AlreadyExistsError, key)
+			}) {
+				t.Error("failed in sub-test")
+			}
+			if !check(t, "with_generated_id", func(t *testing.T) {
+				skipIfNotSupported(t, capabilities.IncompleteKeys, "incomplete keys")
+				singleCreateWithGeneratedIDTest(ctx, t, db, collection)
+			}) {
+				t.Error("failed in sub-test")
+			}
 		}) {
 			return
 		}
-		if !t.Run("exists2", func(t *testing.T) {
+		if !check(t, "exists2", func(t *testing.T) {
 			singleExistsTest(ctx, t, db, key, true)
 		}) {
 			return
 		}
-		if !t.Run("get2", func(t *testing.T) {
+		if !check(t, "get2", func(t *testing.T) {
 			singleGetTest(ctx, t, db, key, true)
 		}) {
 			return
 		}
-		if !t.Run("delete2", func(t *testing.T) {
-			singleDeleteTest(t, db, key)
+		if !check(t, "delete2", func(t *testing.T) {
+			singleDeleteTest(ctx, t, db, key)
 		}) {
 			return
 		}
-		if !t.Run("exists3", func(t *testing.T) {
+		if !check(t, "exists3", func(t *testing.T) {
 			singleExistsTest(ctx, t, db, key, false)
 		}) {
 			return
 		}
+		check(t, "with_parent_key", func(t *testing.T) {
+			skipIfNotSupported(t, capabilities.ParentKeys, "parent keys")
+			singleWithParentKeyTest(ctx, t, db, dal.NewKeyWithParentAndID(key, collection, "r0child"))
+		})
+		check(t, "set_vs_update", func(t *testing.T) {
+			singleSetVsUpdateTest(ctx, t, db, dal.NewKeyWithID(collection, "r1"))
+		})
+	})
+}
+
+func singleWithParentKeyTest(ctx context.Context, t *testing.T, db dal.DB, key *dal.Key) {
+	if !check(t, "delete1", func(t *testing.T) {
+		singleDeleteTest(ctx, t, db, key)
+	}) {
+		return
+	}
+	if !check(t, "create", func(t *testing.T) {
+		singleCreateWithPredefinedIDTest(ctx, t, db, key)
+	}) {
+		return
+	}
+	if !check(t, "get", func(t *testing.T) {
+		singleGetTest(ctx, t, db, key, true)
+	}) {
+		return
+	}
+	if !check(t, "delete2", func(t *testing.T) {
+		singleDeleteTest(ctx, t, db, key)
+	}) {
+		return
+	}
+	check(t, "exists", func(t *testing.T) {
+		singleExistsTest(ctx, t, db, key, false)
 	})
 }
 
-func singleDeleteTest(t *testing.T, db dal.DB, key *dal.Key) {
-	ctx := context.Background()
+// stringPropOnly has a subset of TestData fields
+type stringPropOnly struct {
+	StringProp string `json:"StringProp,omitempty" db:"StringProp"`
+}
+
+// singleSetVsUpdateTest checks Set replaces a whole record while Update changes only named fields
+func singleSetVsUpdateTest(ctx context.Context, t *testing.T, db dal.DB, key *dal.Key) {
+	defer singleDeleteTest(ctx, t, db, key)
+	original := TestData{StringProp: "str1", IntegerProp: 1}
+	setRecord := func(t *testing.T, data any) {
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Set(ctx, dal.NewRecordWithData(key, data))
+		}, dal.TxWithName("singleSetVsUpdateTest"))
+		if err != nil {
+			t.Fatalf("failed to set record: %v", err)
+		}
+	}
+	expectData := func(t *testing.T, expected TestData, message string) {
+		data := new(TestData)
+		if err := db.Get(ctx, dal.NewRecordWithData(key, data)); err != nil {
+			t.Fatalf("failed to get record: %v", err)
+		}
+		if *data != expected {
+			t.Errorf("%s: expected %+v, got %+v", message, expected, *data)
+		}
+	}
+	check(t, "set_replaces_record", func(t *testing.T) {
+		setRecord(t, &original)
+		setRecord(t, &stringPropOnly{StringProp: "str2"})
+		expectData(t, TestData{StringProp: "str2"}, "Set should drop fields missing in new data")
+	})
+	check(t, "update_merges_fields", func(t *testing.T) {
+		setRecord(t, &original)
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Update(ctx, key, []update.Update{update.ByFieldName("StringProp", "str2")})
+		}, dal.TxWithName("singleSetVsUpdateTest"))
+		if err != nil {
+			t.Fatalf("failed to update record: %v", err)
+		}
+		expectData(t, TestData{StringProp: "str2", IntegerProp: 1}, "Update should keep fields that are not updated")
+	})
+}
+
+func singleDeleteTest(ctx context.Context, t *testing.T, db dal.DB, key *dal.Key) {
 	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
 		return tx.Delete(ctx, key)
 	}, dal.TxWithName("singleDeleteTest"))
@@ -127,3 +217,75 @@ func singleCreateWithPredefinedIDTest(ctx context.Context, t *testing.T, db dal.
 		t.Errorf("got unexpected error: %v", err)
 	}
 }
+
+// singleCreateWithDuplicateIDTest expects a record created by singleCreateWithPredefinedIDTest to exist
+func singleCreateWithDuplicateIDTest(ctx context.Context, t *testing.T, db dal.DB, errAlreadyExists error, key *dal.Key) {
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Insert(ctx, dal.NewRecordWithData(key, &TestData{StringProp: "str2", IntegerProp: 2}))
+	}, dal.TxWithName("singleCreateWithDuplicateIDTest"))
+	if err == nil {
+		t.Error("insert of a record with ID of an existing record should fail")
+	} else if !isAlreadyExists(err, errAlreadyExists) {
+		t.Errorf("insert of a record with ID of an existing record should fail with dal.ErrRecordAlreadyExists"+
+			" or an error declared by Capabilities.AlreadyExistsError, got: %v", err)
+	}
+	data := new(TestData)
+	if err = db.Get(ctx, dal.NewRecordWithData(key, data)); err != nil {
+		t.Fatalf("failed to get original record: %v", err)
+	}
+	if expected := (TestData{StringProp: "str1", IntegerProp: 1}); *data != expected {
+		t.Errorf("failed insert should not change the original record: expected %+v, got %+v", expected, *data)
+	}
+}
+
+// isAlreadyExists checks if an error is classified by dalgo as already-exists or matches an error declared by a driver
+func isAlreadyExists(err, errAlreadyExists error) bool {
+	return errors.Is(err, dal.ErrRecordAlreadyExists) || errAlreadyExists != nil && errors.Is(err, errAlreadyExists)
+}
+
+func singleCreateWithGeneratedIDTest(ctx context.Context, t *testing.T, db dal.DB, collection string) {
+	const count = 10
+	records := make([]dal.Record, count)
+	keys := make([]*dal.Key, 0, count)
+	defer func() {
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.DeleteMulti(ctx, keys)
+		}, dal.TxWithName("singleCreateWithGeneratedIDTest cleanup"))
+		if err != nil {
+			t.Errorf("failed to delete records with generated IDs: %v", err)
+		}
+	}()
+	ids := make(map[string]int, count)
+	for i := range records {
+		records[i] = dal.NewRecordWithIncompleteKey(collection, reflect.String, &TestData{
+			StringProp:  fmt.Sprintf("generated%d", i),
+			IntegerProp: i + 1,
+		})
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Insert(ctx, records[i], dal.WithRandomStringID())
+		}, dal.TxWithName("singleCreateWithGeneratedIDTest"))
+		if err != nil {
+			t.Fatalf("failed to insert record #%d with incomplete key: %v", i, err)
+		}
+		key := records[i].Key()
+		id, ok := key.ID.(string)
+		if !ok || id == "" {
+			t.Fatalf("record #%d inserted with incomplete key got no ID: %v", i, key.ID)
+		}
+		keys = append(keys, key)
+		if j, duplicate := ids[id]; duplicate {
+			t.Errorf("records #%d and #%d got the same generated ID: %s", j, i, id)
+		}
+		ids[id] = i
+	}
+	for i, record := range records {
+		data := new(TestData)
+		if err := db.Get(ctx, dal.NewRecordWithData(dal.NewKeyWithID(collection, record.Key().ID), data)); err != nil {
+			t.Errorf("failed to get record #%d by generated ID %v: %v", i, record.Key().ID, err)
+			continue
+		}
+		if expected := record.Data().(*TestData); *data != *expected {
+			t.Errorf("record #%d with generated ID %v: expected %+v, got %+v", i, record.Key().ID, *expected, *data)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package end2end
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/update"
+)
+
+func transactionsOperationsTest(ctx context.Context, t *testing.T, db dal.DB, capabilities Capabilities, collection string) {
+	keys := rollbackKeys{
+		set:    dal.NewKeyWithID(collection, "tx1"),
+		insert: dal.NewKeyWithID(collection, "tx2"),
+		delete: dal.NewKeyWithID(collection, "tx3"),
+	}
+	defer deleteAllRecords(ctx, t, db, []*dal.Key{keys.set, keys.insert, keys.delete})
+
+	check(t, "rollback_on_error", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Transactions, "transactions")
+		keys.setup(ctx, t, db)
+		errRollback := errors.New("rollback")
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			if err := keys.write(ctx, tx); err != nil {
+				return err
+			}
+			return errRollback
+		}, dal.TxWithName("rollback_on_error"))
+		if !errors.Is(err, errRollback) {
+			t.Errorf("transaction should return an error matching the one returned by callback, got: %v", err)
+		}
+		keys.verifyUnchanged(ctx, t, db)
+	})
+
+	check(t, "rollback_on_panic", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Transactions, "transactions")
+		keys.setup(ctx, t, db)
+		const panicValue = "panic inside transaction"
+		var err error
+		recovered := func() (recovered any) {
+			defer func() {
+				recovered = recover()
+			}()
+			err = db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+				if err := keys.write(ctx, tx); err != nil {
+					return err
+				}
+				panic(panicValue)
+			}, dal.TxWithName("rollback_on_panic"))
+			return nil
+		}()
+		if recovered == nil && err == nil {
+			t.Error("transaction should either propagate a panic of callback or return an error")
+		}
+		// a driver that leaks a lock or a connection on panic may block further operations,
+		// so they run in a goroutine that reports an error back instead of using t
+		ctx, cancel := context.WithTimeout(ctx, transactionAfterPanicTimeout)
+		defer cancel()
+		done := make(chan error, 1)
+		go func() {
+			done <- keys.operateAfterPanic(ctx, db)
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-ctx.Done():
+			t.Errorf("operations after a panic inside transaction have not completed in %v", transactionAfterPanicTimeout)
+		}
+	})
+
+	check(t, "readonly_rejects_writes", func(t *testing.T) {
+		keys.setup(ctx, t, db)
+		err := db.RunReadonlyTransaction(ctx, func(ctx context.Context, tx dal.ReadTransaction) error {
+			rwTx, ok := tx.(dal.ReadwriteTransaction)
+			if !ok {
+				return nil // writes are impossible
+			}
+			data := TestData{StringProp: "changed", IntegerProp: 2}
+			if err := rwTx.Set(ctx, dal.NewRecordWithData(keys.set, &data)); err == nil {
+				t.Error("Set inside a read-only transaction should fail")
+			}
+			if err := rwTx.Insert(ctx, dal.NewRecordWithData(keys.insert, &data)); err == nil {
+				t.Error("Insert inside a read-only transaction should fail")
+			}
+			if err := rwTx.Update(ctx, keys.set, []update.Update{update.ByFieldName("IntegerProp", 2)}); err == nil {
+				t.Error("Update inside a read-only transaction should fail")
+			}
+			if err := rwTx.Delete(ctx, keys.delete); err == nil {
+				t.Error("Delete inside a read-only transaction should fail")
+			}
+			return nil
+		}, dal.TxWithName("readonly_rejects_writes"))
+		if err != nil {
+			t.Logf("read-only transaction returned error: %v", err)
+		}
+		keys.verifyUnchanged(ctx, t, db)
+	})
+
+	check(t, "read_your_writes", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Transactions, "transactions")
+		readYourWritesTest(ctx, t, db, capabilities.ReadAfterWriteError, keys.insert, keys.delete)
+	})
+}
+
+// readYourWritesTest sets a record inside a transaction and reads it back in the same transaction.
+// Results of reads are checked after the transaction completes as a driver may retry its worker.
+func readYourWritesTest(ctx context.Context, t *testing.T, db dal.DB, errReadAfterWrite error, key, missingKey *dal.Key) {
+	deleteAllRecords(ctx, t, db, []*dal.Key{missingKey})
+	written := TestData{StringProp: "written", IntegerProp: 3}
+	var (
+		data                           *TestData
+		exists                         bool
+		records                        []dal.Record
+		errGet, errExists, errGetMulti error
+	)
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		if err := tx.Set(ctx, dal.NewRecordWithData(key, &written)); err != nil {
+			return err
+		}
+		data = new(TestData)
+		errGet = tx.Get(ctx, dal.NewRecordWithData(key, data))
+		exists, errExists = tx.Exists(ctx, key)
+		records = []dal.Record{
+			dal.NewRecordWithData(key, new(TestData)),
+			dal.NewRecordWithData(missingKey, new(TestData)),
+		}
+		errGetMulti = tx.GetMulti(ctx, records)
+		return nil
+	}, dal.TxWithName("readYourWritesTest"))
+	if err != nil && errReadAfterWrite == nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+
+	expectRead := func(operation string, err error) bool {
+		if errReadAfterWrite != nil {
+			if !errors.Is(err, errReadAfterWrite) {
+				t.Errorf("%s after write should fail with %v declared by Capabilities.ReadAfterWriteError, got: %v", operation, errReadAfterWrite, err)
+			}
+			return false
+		}
+		if err != nil {
+			t.Errorf("%s after write failed: %v", operation, err)
+			return false
+		}
+		return true
+	}
+	if expectRead("Get", errGet) && *data != written {
+		t.Errorf("Get should return data written by the same transaction: expected %+v, got %+v", written, *data)
+	}
+	if expectRead("Exists", errExists) && !exists {
+		t.Error("Exists should return true for a record written by the same transaction")
+	}
+	if expectRead("GetMulti", errGetMulti) {
+		if !records[0].Exists() {
+			t.Error("GetMulti should return a record written by the same transaction")
+		} else if data := records[0].Data().(*TestData); *data != written {
+			t.Errorf("GetMulti should return data written by the same transaction: expected %+v, got %+v", written, *data)
+		}
+		if records[1].Exists() {
+			t.Error("GetMulti should not return a missing record")
+		}
+	}
+}
+
+// transactionAfterPanicTimeout limits how long operations after a panic inside a transaction can take
+const transactionAfterPanicTimeout = 10 * time.Second
+
+// rollbackKeys are records written by a transaction that is expected to be rolled back
+type rollbackKeys struct {
+	set    *dal.Key // an existing record overwritten by Set
+	insert *dal.Key // a missing record created by Insert
+	delete *dal.Key // an existing record removed by Delete
+}
+
+var rollbackOriginalData = TestData{StringProp: "original", IntegerProp: 1}
+
+func (k rollbackKeys) setup(ctx context.Context, t *testing.T, db dal.DB) {
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		if err := tx.Delete(ctx, k.insert); err != nil {
+			return err
+		}
+		return tx.SetMulti(ctx, []dal.Record{
+			dal.NewRecordWithData(k.set, &rollbackOriginalData),
+			dal.NewRecordWithData(k.delete, &rollbackOriginalData),
+		})
+	}, dal.TxWithName("rollbackKeys.setup"))
+	if err != nil {
+		t.Fatalf("failed to setup records: %v", err)
+	}
+}
+
+func (k rollbackKeys) write(ctx context.Context, tx dal.ReadwriteTransaction) error {
+	data := TestData{StringProp: "changed", IntegerProp: 2}
+	if err := tx.Set(ctx, dal.NewRecordWithData(k.set, &data)); err != nil {
+		return err
+	}
+	if err := tx.Insert(ctx, dal.NewRecordWithData(k.insert, &data)); err != nil {
+		return err
+	}
+	return tx.Delete(ctx, k.delete)
+}
+
+func (k rollbackKeys) verifyUnchanged(ctx context.Context, t *testing.T, db dal.DB) {
+	t.Helper()
+	if err := k.unchanged(ctx, db); err != nil {
+		t.Error(err)
+	}
+}
+
+// unchanged returns an error for every write of a discarded transaction that is visible
+func (k rollbackKeys) unchanged(ctx context.Context, db dal.DB) error {
+	var errs []error
+	data := new(TestData)
+	if err := db.Get(ctx, dal.NewRecordWithData(k.set, data)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to get record overwritten by discarded transaction: %w", err))
+	} else if *data != rollbackOriginalData {
+		errs = append(errs, fmt.Errorf("record overwritten by discarded transaction has changed: expected %+v, got %+v", rollbackOriginalData, *data))
+	}
+	if exists, err := db.Exists(ctx, k.insert); err != nil {
+		errs = append(errs, fmt.Errorf("failed to check existence of record inserted by discarded transaction: %w", err))
+	} else if exists {
+		errs = append(errs, errors.New("record inserted by discarded transaction exists"))
+	}
+	if exists, err := db.Exists(ctx, k.delete); err != nil {
+		errs = append(errs, fmt.Errorf("failed to check existence of record deleted by discarded transaction: %w", err))
+	} else if !exists {
+		errs = append(errs, errors.New("record deleted by discarded transaction does not exist"))
+	}
+	return errors.Join(errs...)
+}
+
+// operateAfterPanic checks records are unchanged and can be written after a panic inside a transaction
+func (k rollbackKeys) operateAfterPanic(ctx context.Context, db dal.DB) error {
+	if err := k.unchanged(ctx, db); err != nil {
+		return err
+	}
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Set(ctx, dal.NewRecordWithData(k.insert, &rollbackOriginalData))
+	}, dal.TxWithName("after_rollback_on_panic"))
+	if err != nil {
+		return fmt.Errorf("transaction after a panic failed: %w", err)
+	}
+	if exists, err := db.Exists(ctx, k.insert); err != nil {
+		return fmt.Errorf("failed to check existence of record set after a panic: %w", err)
+	} else if !exists {
+		return errors.New("record set by a transaction after a panic does not exist")
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package end2end
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/dal-go/dalgo/dal"
+	"github.com/dal-go/dalgo/update"
+)
+
+func updateOperationsTest(ctx context.Context, t *testing.T, db dal.DB, options Options, collection string) {
+	capabilities := options.Capabilities
+	key := dal.NewKeyWithID(collection, "u1")
+	nestedKey := dal.NewKeyWithID(collection, "u2") // a separate record as drivers storing flat rows do not support nested fields
+	defer singleDeleteTest(ctx, t, db, key)
+	defer singleDeleteTest(ctx, t, db, nestedKey)
+
+	original := TestData{StringProp: "str1", IntegerProp: 1}
+	testUpdate := func(name string, updates []update.Update, expected TestData) {
+		check(t, name, func(t *testing.T) {
+			setUpdatedRecord(ctx, t, db, key, &original)
+			updateSingleRecord(ctx, t, db, key, updates)
+			verifyUpdatedRecord(ctx, t, db, options, key, expected, expected.IntegerProp, false)
+		})
+	}
+
+	expected := original
+	expected.StringProp = "str2"
+	testUpdate("string_field", []update.Update{update.ByFieldName("StringProp", "str2")}, expected)
+
+	expected = original
+	expected.IntegerProp = 2
+	testUpdate("integer_field", []update.Update{update.ByFieldName("IntegerProp", 2)}, expected)
+
+	expected = original
+	expected.StringProp, expected.IntegerProp = "str3", 3
+	testUpdate("multiple_fields", []update.Update{
+		update.ByFieldName("StringProp", "str3"),
+		update.ByFieldName("IntegerProp", 3),
+	}, expected)
+
+	check(t, "increment", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Increments, "increments")
+		setUpdatedRecord(ctx, t, db, key, &original)
+		updateSingleRecord(ctx, t, db, key, []update.Update{update.ByFieldName("IntegerProp", update.Increment(5))})
+		expected := original
+		expected.IntegerProp += 5
+		verifyUpdatedRecord(ctx, t, db, options, key, expected, expected.IntegerProp, true)
+	})
+
+	check(t, "concurrent_increments", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.Increments, "increments")
+		setUpdatedRecord(ctx, t, db, key, &original)
+		const concurrency = 5
+		var wg sync.WaitGroup
+		errs := make([]error, concurrency)
+		for i := range concurrency {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+					return tx.Update(ctx, key, []update.Update{update.ByFieldName("IntegerProp", update.Increment(1))})
+				}, dal.TxWithName(fmt.Sprintf("concurrent_increments#%d", i)))
+			}()
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				t.Errorf("increment #%d failed: %v", i, err)
+			}
+		}
+		expected := original
+		expected.IntegerProp += concurrency
+		verifyUpdatedRecord(ctx, t, db, options, key, expected, expected.IntegerProp, true)
+	})
+
+	nestedOriginal := TestNestedData{
+		TestData: original,
+		Address:  TestAddress{City: "Dublin", Street: "O'Connell"},
+	}
+	check(t, "field_path", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.NestedFields, "nested fields")
+		setUpdatedRecord(ctx, t, db, nestedKey, &nestedOriginal)
+		updateSingleRecord(ctx, t, db, nestedKey, []update.Update{update.ByFieldPath(update.FieldPath{"Address", "City"}, "Cork")})
+		expected := nestedOriginal
+		expected.Address.City = "Cork"
+		verifyUpdatedRecord(ctx, t, db, options, nestedKey, expected, expected.IntegerProp, false)
+	})
+
+	check(t, "delete_field", func(t *testing.T) {
+		skipIfNotSupported(t, capabilities.NestedFields, "nested fields")
+		setUpdatedRecord(ctx, t, db, nestedKey, &nestedOriginal)
+		updateSingleRecord(ctx, t, db, nestedKey, []update.Update{
+			update.ByFieldName("StringProp", update.DeleteField),
+			update.ByFieldPath(update.FieldPath{"Address", "Street"}, update.DeleteField),
+		})
+		expected := nestedOriginal
+		expected.StringProp, expected.Address.Street = "", ""
+		verifyUpdatedRecord(ctx, t, db, options, nestedKey, expected, expected.IntegerProp, true)
+	})
+
+	check(t, "missing_record", func(t *testing.T) {
+		missingKey := dal.NewKeyWithID(collection, "u9")
+		singleDeleteTest(ctx, t, db, missingKey)
+		err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+			return tx.Update(ctx, missingKey, []update.Update{update.ByFieldName("StringProp", "str2")})
+		}, dal.TxWithName("updateOperationsTest missing_record"))
+		if !dal.IsNotFound(err) {
+			t.Errorf("update of a missing record should fail with not found error, got: %v", err)
+		}
+		singleExistsTest(ctx, t, db, missingKey, false)
+	})
+}
+
+func updateSingleRecord(ctx context.Context, t *testing.T, db dal.DB, key *dal.Key, updates []update.Update) {
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Update(ctx, key, updates)
+	}, dal.TxWithName("updateSingleRecord"))
+	if err != nil {
+		t.Fatalf("failed to update record: %v", err)
+	}
+}
+
+// setUpdatedRecord sets a record to original data before an update
+func setUpdatedRecord[D any](ctx context.Context, t *testing.T, db dal.DB, key *dal.Key, original *D) {
+	err := db.RunReadwriteTransaction(ctx, func(ctx context.Context, tx dal.ReadwriteTransaction) error {
+		return tx.Set(ctx, dal.NewRecordWithData(key, original))
+	}, dal.TxWithName("updateOperationsTest setup"))
+	if err != nil {
+		t.Fatalf("failed to set record to update: %v", err)
+	}
+}
+
+// verifyUpdatedRecord checks Get returns expected data of an updated record,
+// and a query by value of IntegerProp does the same if byQuery is true and the driver supports such queries
+func verifyUpdatedRecord[D comparable](ctx context.Context, t *testing.T, db dal.DB, options Options, key *dal.Key, expected D, integerProp int, byQuery bool) {
+	data := new(D)
+	if err := db.Get(ctx, dal.NewRecordWithData(key, data)); err != nil {
+		t.Fatalf("failed to get updated record: %v", err)
+	}
+	if *data != expected {
+		t.Errorf("unexpected data after update:\nexpected: %+v\n  actual: %+v", expected, *data)
+	}
+	if byQuery && options.Capabilities.Queries && options.Capabilities.SupportsWhereOperator(dal.Equal) {
+		verifyUpdatedRecordByQuery(ctx, t, db, options, key, expected, integerProp)
+	}
+}
+
+// verifyUpdatedRecordByQuery checks a query by value of IntegerProp returns the updated record with expected data
+func verifyUpdatedRecordByQuery[D comparable](ctx context.Context, t *testing.T, db dal.DB, options Options, key *dal.Key, expected D, integerProp int) {
+	q := dal.From(dal.NewRootCollectionRef(key.Collection(), "")).NewQuery().
+		WhereField("IntegerProp", dal.Equal, integerProp).
+		SelectIntoRecord(func() dal.Record {
+			return dal.NewRecordWithIncompleteKey(key.Collection(), reflect.String, new(D))
+		})
+	queryUpdatedRecord := func(ctx context.Context) error {
+		records, err := dal.ExecuteQueryAndReadAllToRecords(ctx, q, db)
+		if err != nil {
+			return fmt.Errorf("failed to query updated record: %w", err)
+		}
+		for _, record := range records {
+			if record.Key().ID != key.ID {
+				continue
+			}
+			if actual := *record.Data().(*D); actual != expected {
+				return fmt.Errorf("unexpected data of updated record returned by query:\nexpected: %+v\n  actual: %+v", expected, actual)
+			}
+			return nil
+		}
+		return fmt.Errorf("query by IntegerProp=%d has not returned updated record %v", integerProp, key.ID)
+	}
+	if err := options.whenConsistent(ctx, queryUpdatedRecord); err != nil {
+		t.Error(err)
+	}
+}